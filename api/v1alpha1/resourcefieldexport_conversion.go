@@ -0,0 +1,229 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/deliveryhero/field-exporter/api/v1beta1"
+)
+
+// ConvertTo converts this ResourceFieldExport to the hub version.
+func (src *ResourceFieldExport) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.ResourceFieldExport)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = specToV1beta1(src.Spec)
+	dst.Status = statusToV1beta1(src.Status)
+	return nil
+}
+
+// ConvertFrom converts from the hub version to this ResourceFieldExport.
+func (dst *ResourceFieldExport) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.ResourceFieldExport)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = specFromV1beta1(src.Spec)
+	dst.Status = statusFromV1beta1(src.Status)
+	return nil
+}
+
+func specToV1beta1(in ResourceFieldExportSpec) v1beta1.ResourceFieldExportSpec {
+	out := v1beta1.ResourceFieldExportSpec{
+		To: v1beta1.DestinationRef{
+			Type:         v1beta1.DestinationType(in.To.Type),
+			Name:         in.To.Name,
+			Namespace:    in.To.Namespace,
+			CreatePolicy: v1beta1.CreatePolicy(in.To.CreatePolicy),
+		},
+		DeletionPolicy: v1beta1.DeletionPolicy(in.DeletionPolicy),
+		DryRun:         in.DryRun,
+	}
+	if in.From != nil {
+		from := v1beta1.ResourceRef(*in.From)
+		out.From = &from
+	}
+	if in.Sources != nil {
+		out.Sources = make([]v1beta1.Source, len(in.Sources))
+		for i, s := range in.Sources {
+			out.Sources[i] = v1beta1.Source{Alias: s.Alias, ResourceRef: v1beta1.ResourceRef(s.ResourceRef)}
+		}
+	}
+	if in.RequiredFields != nil {
+		out.RequiredFields = &v1beta1.RequiredFields{}
+		if in.RequiredFields.StatusConditions != nil {
+			out.RequiredFields.StatusConditions = make([]v1beta1.StatusCondition, len(in.RequiredFields.StatusConditions))
+			for i, c := range in.RequiredFields.StatusConditions {
+				out.RequiredFields.StatusConditions[i] = v1beta1.StatusCondition(c)
+			}
+		}
+	}
+	if in.Outputs != nil {
+		out.Outputs = make([]v1beta1.Output, len(in.Outputs))
+		for i, o := range in.Outputs {
+			out.Outputs[i] = v1beta1.Output{
+				Key:      o.Key,
+				Path:     o.Path,
+				Template: o.Template,
+				Format:   v1beta1.OutputFormat(o.Format),
+				OnNull:   v1beta1.NullPolicy(o.OnNull),
+				Default:  o.Default,
+				Optional: o.Optional,
+			}
+			if o.Fields != nil {
+				out.Outputs[i].Fields = make([]v1beta1.Field, len(o.Fields))
+				for j, f := range o.Fields {
+					out.Outputs[i].Fields[j] = v1beta1.Field(f)
+				}
+			}
+		}
+	}
+	return out
+}
+
+func specFromV1beta1(in v1beta1.ResourceFieldExportSpec) ResourceFieldExportSpec {
+	out := ResourceFieldExportSpec{
+		To: DestinationRef{
+			Type:         DestinationType(in.To.Type),
+			Name:         in.To.Name,
+			Namespace:    in.To.Namespace,
+			CreatePolicy: CreatePolicy(in.To.CreatePolicy),
+		},
+		DeletionPolicy: DeletionPolicy(in.DeletionPolicy),
+		DryRun:         in.DryRun,
+	}
+	if in.From != nil {
+		from := ResourceRef(*in.From)
+		out.From = &from
+	}
+	if in.Sources != nil {
+		out.Sources = make([]Source, len(in.Sources))
+		for i, s := range in.Sources {
+			out.Sources[i] = Source{Alias: s.Alias, ResourceRef: ResourceRef(s.ResourceRef)}
+		}
+	}
+	if in.RequiredFields != nil {
+		out.RequiredFields = &RequiredFields{}
+		if in.RequiredFields.StatusConditions != nil {
+			out.RequiredFields.StatusConditions = make([]StatusCondition, len(in.RequiredFields.StatusConditions))
+			for i, c := range in.RequiredFields.StatusConditions {
+				out.RequiredFields.StatusConditions[i] = StatusCondition(c)
+			}
+		}
+	}
+	if in.Outputs != nil {
+		out.Outputs = make([]Output, len(in.Outputs))
+		for i, o := range in.Outputs {
+			out.Outputs[i] = Output{
+				Key:      o.Key,
+				Path:     o.Path,
+				Template: o.Template,
+				Format:   OutputFormat(o.Format),
+				OnNull:   NullPolicy(o.OnNull),
+				Default:  o.Default,
+				Optional: o.Optional,
+			}
+			if o.Fields != nil {
+				out.Outputs[i].Fields = make([]Field, len(o.Fields))
+				for j, f := range o.Fields {
+					out.Outputs[i].Fields[j] = Field(f)
+				}
+			}
+		}
+	}
+	return out
+}
+
+func statusToV1beta1(in ResourceFieldExportStatus) v1beta1.ResourceFieldExportStatus {
+	out := v1beta1.ResourceFieldExportStatus{
+		ObservedGeneration: in.ObservedGeneration,
+		LastSyncTime:       in.LastSyncTime,
+		Conditions:         in.Conditions,
+		ExportedKeys:       in.ExportedKeys,
+	}
+	if in.ExportedTo != nil {
+		out.ExportedTo = &v1beta1.ExportedDestination{
+			Type:      v1beta1.DestinationType(in.ExportedTo.Type),
+			Name:      in.ExportedTo.Name,
+			Namespace: in.ExportedTo.Namespace,
+		}
+	}
+	if in.Fallbacks != nil {
+		out.Fallbacks = make([]v1beta1.OutputFallback, len(in.Fallbacks))
+		for i, f := range in.Fallbacks {
+			out.Fallbacks[i] = v1beta1.OutputFallback{Key: f.Key, Policy: v1beta1.NullPolicy(f.Policy)}
+		}
+	}
+	if in.Outputs != nil {
+		out.Outputs = make([]v1beta1.OutputStatus, len(in.Outputs))
+		for i, o := range in.Outputs {
+			out.Outputs[i] = v1beta1.OutputStatus{
+				Key:         o.Key,
+				State:       v1beta1.OutputState(o.State),
+				LastError:   o.LastError,
+				Hash:        o.Hash,
+				LastChanged: o.LastChanged,
+			}
+		}
+	}
+	if in.Preview != nil {
+		out.Preview = make([]v1beta1.PreviewEntry, len(in.Preview))
+		for i, p := range in.Preview {
+			out.Preview[i] = v1beta1.PreviewEntry(p)
+		}
+	}
+	return out
+}
+
+func statusFromV1beta1(in v1beta1.ResourceFieldExportStatus) ResourceFieldExportStatus {
+	out := ResourceFieldExportStatus{
+		ObservedGeneration: in.ObservedGeneration,
+		LastSyncTime:       in.LastSyncTime,
+		Conditions:         in.Conditions,
+		ExportedKeys:       in.ExportedKeys,
+	}
+	if in.ExportedTo != nil {
+		out.ExportedTo = &ExportedDestination{
+			Type:      DestinationType(in.ExportedTo.Type),
+			Name:      in.ExportedTo.Name,
+			Namespace: in.ExportedTo.Namespace,
+		}
+	}
+	if in.Fallbacks != nil {
+		out.Fallbacks = make([]OutputFallback, len(in.Fallbacks))
+		for i, f := range in.Fallbacks {
+			out.Fallbacks[i] = OutputFallback{Key: f.Key, Policy: NullPolicy(f.Policy)}
+		}
+	}
+	if in.Outputs != nil {
+		out.Outputs = make([]OutputStatus, len(in.Outputs))
+		for i, o := range in.Outputs {
+			out.Outputs[i] = OutputStatus{
+				Key:         o.Key,
+				State:       OutputState(o.State),
+				LastError:   o.LastError,
+				Hash:        o.Hash,
+				LastChanged: o.LastChanged,
+			}
+		}
+	}
+	if in.Preview != nil {
+		out.Preview = make([]PreviewEntry, len(in.Preview))
+		for i, p := range in.Preview {
+			out.Preview[i] = PreviewEntry(p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package v1alpha1
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+
+	"github.com/deliveryhero/field-exporter/api/v1beta1"
+)
+
+const fuzzIterations = 1000
+
+func newFuzzer(t *testing.T) interface{ Fuzz(any) } {
+	scheme := runtime.NewScheme()
+	require.NoError(t, AddToScheme(scheme))
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+	return fuzzer.FuzzerFor(metafuzzer.Funcs, rand.NewSource(rand.Int63()), serializer.NewCodecFactory(scheme))
+}
+
+func TestConversionRoundTrip(t *testing.T) {
+	t.Run("v1alpha1 to v1beta1 and back", func(t *testing.T) {
+		f := newFuzzer(t)
+		for i := 0; i < fuzzIterations; i++ {
+			spoke := &ResourceFieldExport{}
+			f.Fuzz(spoke)
+			// the type meta is set by the conversion webhook, not the conversion functions
+			spoke.TypeMeta = metav1.TypeMeta{}
+
+			hub := &v1beta1.ResourceFieldExport{}
+			require.NoError(t, spoke.ConvertTo(hub))
+			converted := &ResourceFieldExport{}
+			require.NoError(t, converted.ConvertFrom(hub))
+			require.True(t, apiequality.Semantic.DeepEqual(spoke, converted), "round trip changed %#v to %#v", spoke, converted)
+		}
+	})
+
+	t.Run("v1beta1 to v1alpha1 and back", func(t *testing.T) {
+		f := newFuzzer(t)
+		for i := 0; i < fuzzIterations; i++ {
+			hub := &v1beta1.ResourceFieldExport{}
+			f.Fuzz(hub)
+			hub.TypeMeta = metav1.TypeMeta{}
+
+			spoke := &ResourceFieldExport{}
+			require.NoError(t, spoke.ConvertFrom(hub))
+			converted := &v1beta1.ResourceFieldExport{}
+			require.NoError(t, spoke.ConvertTo(converted))
+			require.True(t, apiequality.Semantic.DeepEqual(hub, converted), "round trip changed %#v to %#v", hub, converted)
+		}
+	})
+}
@@ -21,44 +21,51 @@ limitations under the License.
 package v1alpha1
 
 import (
-	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Condition) DeepCopyInto(out *Condition) {
+func (in *DestinationRef) DeepCopyInto(out *DestinationRef) {
 	*out = *in
-	if in.LastTransitionTime != nil {
-		in, out := &in.LastTransitionTime, &out.LastTransitionTime
-		*out = (*in).DeepCopy()
-	}
-	if in.Message != nil {
-		in, out := &in.Message, &out.Message
-		*out = new(string)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DestinationRef.
+func (in *DestinationRef) DeepCopy() *DestinationRef {
+	if in == nil {
+		return nil
 	}
+	out := new(DestinationRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportedDestination) DeepCopyInto(out *ExportedDestination) {
+	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
-func (in *Condition) DeepCopy() *Condition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportedDestination.
+func (in *ExportedDestination) DeepCopy() *ExportedDestination {
 	if in == nil {
 		return nil
 	}
-	out := new(Condition)
+	out := new(ExportedDestination)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DestinationRef) DeepCopyInto(out *DestinationRef) {
+func (in *Field) DeepCopyInto(out *Field) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DestinationRef.
-func (in *DestinationRef) DeepCopy() *DestinationRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Field.
+func (in *Field) DeepCopy() *Field {
 	if in == nil {
 		return nil
 	}
-	out := new(DestinationRef)
+	out := new(Field)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -66,6 +73,16 @@ func (in *DestinationRef) DeepCopy() *DestinationRef {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Output) DeepCopyInto(out *Output) {
 	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]Field, len(*in))
+		copy(*out, *in)
+	}
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Output.
@@ -78,6 +95,55 @@ func (in *Output) DeepCopy() *Output {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutputFallback) DeepCopyInto(out *OutputFallback) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputFallback.
+func (in *OutputFallback) DeepCopy() *OutputFallback {
+	if in == nil {
+		return nil
+	}
+	out := new(OutputFallback)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutputStatus) DeepCopyInto(out *OutputStatus) {
+	*out = *in
+	if in.LastChanged != nil {
+		in, out := &in.LastChanged, &out.LastChanged
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputStatus.
+func (in *OutputStatus) DeepCopy() *OutputStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OutputStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreviewEntry) DeepCopyInto(out *PreviewEntry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreviewEntry.
+func (in *PreviewEntry) DeepCopy() *PreviewEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(PreviewEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RequiredFields) DeepCopyInto(out *RequiredFields) {
 	*out = *in
@@ -160,7 +226,16 @@ func (in *ResourceFieldExportList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceFieldExportSpec) DeepCopyInto(out *ResourceFieldExportSpec) {
 	*out = *in
-	out.From = in.From
+	if in.From != nil {
+		in, out := &in.From, &out.From
+		*out = new(ResourceRef)
+		**out = **in
+	}
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]Source, len(*in))
+		copy(*out, *in)
+	}
 	out.To = in.To
 	if in.RequiredFields != nil {
 		in, out := &in.RequiredFields, &out.RequiredFields
@@ -170,7 +245,9 @@ func (in *ResourceFieldExportSpec) DeepCopyInto(out *ResourceFieldExportSpec) {
 	if in.Outputs != nil {
 		in, out := &in.Outputs, &out.Outputs
 		*out = make([]Output, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -187,13 +264,44 @@ func (in *ResourceFieldExportSpec) DeepCopy() *ResourceFieldExportSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceFieldExportStatus) DeepCopyInto(out *ResourceFieldExportStatus) {
 	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]Condition, len(*in))
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExportedKeys != nil {
+		in, out := &in.ExportedKeys, &out.ExportedKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExportedTo != nil {
+		in, out := &in.ExportedTo, &out.ExportedTo
+		*out = new(ExportedDestination)
+		**out = **in
+	}
+	if in.Fallbacks != nil {
+		in, out := &in.Fallbacks, &out.Fallbacks
+		*out = make([]OutputFallback, len(*in))
+		copy(*out, *in)
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = make([]OutputStatus, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Preview != nil {
+		in, out := &in.Preview, &out.Preview
+		*out = make([]PreviewEntry, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceFieldExportStatus.
@@ -221,6 +329,22 @@ func (in *ResourceRef) DeepCopy() *ResourceRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Source) DeepCopyInto(out *Source) {
+	*out = *in
+	out.ResourceRef = in.ResourceRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Source.
+func (in *Source) DeepCopy() *Source {
+	if in == nil {
+		return nil
+	}
+	out := new(Source)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StatusCondition) DeepCopyInto(out *StatusCondition) {
 	*out = *in
@@ -0,0 +1,104 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ExportableResourceSpec defines a kind exports can read from in addition to the allowed API groups
+type ExportableResourceSpec struct {
+	// Group is the API group of the kind, e.g. cert-manager.io
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="group is immutable"
+	Group string `json:"group"`
+	// Kind is the kind of the resource, e.g. Certificate
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="kind is immutable"
+	Kind string `json:"kind"`
+	// Versions limits the registration to these versions, every served version is registered
+	// when empty
+	// +optional
+	Versions []string `json:"versions,omitempty"`
+}
+
+// Reasons of the Ready condition of an ExportableResource
+const (
+	// ReasonRegistered is set when exports can read from the kind
+	ReasonRegistered = "Registered"
+	// ReasonKindNotFound is set when the cluster doesn't serve the kind
+	ReasonKindNotFound = "KindNotFound"
+	// ReasonForbidden is set when the manager isn't allowed to read the kind
+	ReasonForbidden = "Forbidden"
+)
+
+// ExportableResourceStatus defines the observed state of ExportableResource
+type ExportableResourceStatus struct {
+	// ObservedGeneration is the generation of the spec the status was computed for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Resource is the plural resource name of the kind
+	// +optional
+	Resource string `json:"resource,omitempty"`
+	// Versions are the registered versions
+	// +optional
+	Versions []string `json:"versions,omitempty"`
+	// Rules are the permissions the manager needs to read the kind, grant them with a ClusterRole
+	// labelled gdp.deliveryhero.io/aggregate-to-source-reader: "true"
+	// +optional
+	Rules []rbacv1.PolicyRule `json:"rules,omitempty"`
+	// Conditions are the standard conditions of the registration, Ready reports whether exports
+	// can read from the kind
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster,shortName=exr,categories=field-exporter
+//+kubebuilder:printcolumn:name="Group",type=string,JSONPath=`.spec.group`
+//+kubebuilder:printcolumn:name="Kind",type=string,JSONPath=`.spec.kind`
+//+kubebuilder:printcolumn:name="Versions",type=string,JSONPath=`.status.versions`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ExportableResource registers a kind outside the allowed API groups, e.g. of an in-house operator,
+// so ResourceFieldExports can read from it
+type ExportableResource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ExportableResourceSpec   `json:"spec,omitempty"`
+	Status ExportableResourceStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ExportableResourceList contains a list of ExportableResource
+type ExportableResourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ExportableResource `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ExportableResource{}, &ExportableResourceList{})
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GrantFrom is a namespace whose exports are granted access
+type GrantFrom struct {
+	// Namespace is the namespace of the ResourceFieldExports
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Namespace string `json:"namespace"`
+}
+
+// GrantTo is a resource of the namespace of the grant exports may read from or write to
+type GrantTo struct {
+	// Group is the API group of the resource, empty for ConfigMaps and Secrets
+	// +optional
+	Group string `json:"group,omitempty"`
+	// Kind is the kind of the resource, e.g. ConfigMap, Secret or RedisInstance
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+	// Name limits the grant to the resource with this name, every resource of the kind is
+	// granted when empty
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// FieldExportGrantSpec defines which exports may refer to which resources of the namespace
+type FieldExportGrantSpec struct {
+	// From are the namespaces whose exports may refer to the resources
+	// +kubebuilder:validation:MinItems=1
+	From []GrantFrom `json:"from"`
+	// To are the resources exports may read from as a source or write to as a destination
+	// +kubebuilder:validation:MinItems=1
+	To []GrantTo `json:"to"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:shortName=feg,categories=field-exporter
+//+kubebuilder:printcolumn:name="From",type=string,JSONPath=`.spec.from[*].namespace`
+//+kubebuilder:printcolumn:name="Kinds",type=string,JSONPath=`.spec.to[*].kind`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// FieldExportGrant allows ResourceFieldExports of other namespaces to read sources from or write
+// destinations to its namespace. Like a Gateway API ReferenceGrant it lives in the namespace that
+// is referred to, so a cross-namespace export needs the consent of both namespaces.
+type FieldExportGrant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FieldExportGrantSpec `json:"spec,omitempty"`
+}
+
+// Permits reports whether the grant allows exports of the namespace to refer to the resource.
+func (g *FieldExportGrant) Permits(namespace, group, kind, name string) bool {
+	from := false
+	for _, f := range g.Spec.From {
+		from = from || f.Namespace == namespace
+	}
+	if !from {
+		return false
+	}
+	for _, t := range g.Spec.To {
+		if t.Group == group && t.Kind == kind && (t.Name == "" || t.Name == name) {
+			return true
+		}
+	}
+	return false
+}
+
+//+kubebuilder:object:root=true
+
+// FieldExportGrantList contains a list of FieldExportGrant
+type FieldExportGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FieldExportGrant `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FieldExportGrant{}, &FieldExportGrantList{})
+}
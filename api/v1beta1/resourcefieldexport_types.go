@@ -0,0 +1,383 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type ResourceRef struct {
+	// APIVersion is the group version of the resource
+	// +kubebuilder:validation:Pattern=^([a-zA-Z0-9.-]+[a-zA-Z0-9-]\/[a-zA-Z0-9]+|[a-zA-Z0-9]+)$
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	// Namespace is the namespace of the resource, the namespace of the export when empty. A
+	// resource of another namespace must be granted by a FieldExportGrant in that namespace.
+	// Cluster scoped resources have no namespace, it must not be set for them.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Source is a resource the outputs are read from.
+type Source struct {
+	// Alias is the key of the resource in the input of the output queries, e.g. `.db.status.ipAddress`
+	// +kubebuilder:validation:Pattern=^[a-zA-Z_][a-zA-Z0-9_]*$
+	Alias       string `json:"alias"`
+	ResourceRef `json:",inline"`
+}
+
+// DestinationType is a ConfigMap or a Secret
+// +kubebuilder:validation:Enum=ConfigMap;Secret
+type DestinationType string
+
+const (
+	ConfigMap DestinationType = "ConfigMap"
+	Secret    DestinationType = "Secret"
+)
+
+// CreatePolicy defines what happens when the destination does not exist
+// +kubebuilder:validation:Enum=MustExist;CreateIfMissing
+type CreatePolicy string
+
+const (
+	// MustExist fails the export when the destination is missing
+	MustExist CreatePolicy = "MustExist"
+	// CreateIfMissing creates the destination, owned by the ResourceFieldExport
+	CreateIfMissing CreatePolicy = "CreateIfMissing"
+)
+
+// DestinationRef is where the fields should be written.
+// +kubebuilder:validation:XValidation:rule="!has(self.namespace) || !has(self.createPolicy) || self.createPolicy != 'CreateIfMissing'",message="createPolicy CreateIfMissing can't be combined with a namespace"
+type DestinationRef struct {
+	Type DestinationType `json:"type"`
+	Name string          `json:"name"`
+	// Namespace is the namespace of the destination, the namespace of the export when empty. A
+	// destination in another namespace must exist and be granted by a FieldExportGrant in that
+	// namespace.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Namespace string `json:"namespace,omitempty"`
+	// CreatePolicy controls whether a missing destination is created. A destination created
+	// by the controller is owned by the ResourceFieldExport and garbage collected with it.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=MustExist
+	CreatePolicy CreatePolicy `json:"createPolicy,omitempty"`
+}
+
+// ExportedDestination is the ConfigMap or Secret an export wrote its keys to
+type ExportedDestination struct {
+	Type      DestinationType `json:"type"`
+	Name      string          `json:"name"`
+	Namespace string          `json:"namespace"`
+}
+
+// DeletionPolicy defines what happens to the exported values when the export is deleted
+// +kubebuilder:validation:Enum=Retain;Delete
+type DeletionPolicy string
+
+const (
+	// Retain leaves the exported values in the destination
+	Retain DeletionPolicy = "Retain"
+	// Delete removes the exported values from the destination
+	Delete DeletionPolicy = "Delete"
+)
+
+// OutputFormat is the file format a structured output value is serialized in
+// +kubebuilder:validation:Enum=JSON;YAML;Dotenv;Properties
+type OutputFormat string
+
+const (
+	JSON       OutputFormat = "JSON"
+	YAML       OutputFormat = "YAML"
+	Dotenv     OutputFormat = "Dotenv"
+	Properties OutputFormat = "Properties"
+)
+
+// NullPolicy defines how an output whose path resolves to null is handled
+// +kubebuilder:validation:Enum=Error;Empty;Skip;Default
+type NullPolicy string
+
+const (
+	// NullError fails the export
+	NullError NullPolicy = "Error"
+	// NullEmpty writes an empty value
+	NullEmpty NullPolicy = "Empty"
+	// NullSkip leaves the key out of the destination
+	NullSkip NullPolicy = "Skip"
+	// NullDefault writes the default value of the output
+	NullDefault NullPolicy = "Default"
+)
+
+// Field is an entry of a formatted output
+type Field struct {
+	// Key is the key of the entry, entries of a Dotenv output must be valid variable names
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[-._a-zA-Z0-9]+$`
+	Key string `json:"key"`
+	// Path is a jq query selecting the value of the entry
+	Path string `json:"path"`
+}
+
+// +kubebuilder:validation:XValidation:rule="[has(self.path), has(self.template), has(self.fields)].filter(x, x).size() == 1",message="exactly one of path, template or fields must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.fields) || has(self.format)",message="fields require a format"
+// +kubebuilder:validation:XValidation:rule="!has(self.template) || !has(self.format)",message="template can't be combined with a format"
+// +kubebuilder:validation:XValidation:rule="!has(self.onNull) || self.onNull != 'Default' || has(self.default)",message="onNull Default requires a default"
+// +kubebuilder:validation:XValidation:rule="!has(self.optional) || !self.optional || !has(self.default)",message="optional can't be combined with a default"
+// +kubebuilder:validation:XValidation:rule="!has(self.optional) || !self.optional || !has(self.onNull) || self.onNull == 'Skip'",message="optional can't be combined with onNull other than Skip"
+type Output struct {
+	// Key is the key of the value in the destination
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[-._a-zA-Z0-9]+$`
+	Key string `json:"key"`
+	// Path is a jq query selecting the value of the key
+	// +kubebuilder:validation:Optional
+	Path string `json:"path,omitempty"`
+	// Template is a Go text/template composing the value of the key, it is executed against
+	// the same input as the path queries, e.g. `redis://{{ .status.host }}:{{ .status.port }}`
+	// +kubebuilder:validation:Optional
+	Template string `json:"template,omitempty"`
+	// Format serializes the value of the key as a file, e.g. an `application.yaml` or `.env`
+	// file. The value is either the object selected by path or built from fields.
+	// +kubebuilder:validation:Optional
+	Format OutputFormat `json:"format,omitempty"`
+	// Fields are the entries of a formatted output
+	// +kubebuilder:validation:Optional
+	Fields []Field `json:"fields,omitempty"`
+	// OnNull defines how a path resolving to null, or to nothing at all, is handled. It is
+	// Default when a default is set and Error otherwise.
+	// +kubebuilder:validation:Optional
+	OnNull NullPolicy `json:"onNull,omitempty"`
+	// Default is the value written when the path resolves to null
+	// +kubebuilder:validation:Optional
+	Default *string `json:"default,omitempty"`
+	// Optional leaves the key out of the destination when the path resolves to null, it is
+	// a shorthand for onNull Skip
+	// +kubebuilder:validation:Optional
+	Optional bool `json:"optional,omitempty"`
+}
+
+// NullHandling returns the null policy in effect for the output.
+func (o Output) NullHandling() NullPolicy {
+	switch {
+	case o.Optional:
+		return NullSkip
+	case o.OnNull != "":
+		return o.OnNull
+	case o.Default != nil:
+		return NullDefault
+	default:
+		return NullError
+	}
+}
+
+type RequiredFields struct {
+	// +kubebuilder:validation:Optional
+	StatusConditions []StatusCondition `json:"statusConditions"`
+}
+
+type StatusCondition struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+// ResourceFieldExportSpec defines the desired state of ResourceFieldExport
+// +kubebuilder:validation:XValidation:rule="has(self.from) != has(self.sources)",message="exactly one of from or sources must be set"
+type ResourceFieldExportSpec struct {
+	// From is the resource the outputs are read from, queries run against the resource itself.
+	// +kubebuilder:validation:Optional
+	From *ResourceRef `json:"from,omitempty"`
+	// Sources are the resources the outputs are read from, queries run against an object
+	// holding each resource under its alias.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MinItems=1
+	// +listType=map
+	// +listMapKey=alias
+	Sources []Source       `json:"sources,omitempty"`
+	To      DestinationRef `json:"to"`
+
+	// +kubebuilder:validation:Optional
+	RequiredFields *RequiredFields `json:"requiredFields"`
+	// +kubebuilder:validation:MinItems=1
+	Outputs []Output `json:"outputs"`
+
+	// DeletionPolicy controls whether the exported keys are removed from the destination
+	// when the export is deleted. A destination created by the controller is deleted as a whole.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=Retain
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// DryRun resolves the outputs into status.preview without writing the destination
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// ReadyCondition is the condition reporting whether the outputs were written to the destination
+const ReadyCondition = "Ready"
+
+// Reasons of the Ready condition
+const (
+	// ReasonSynced is set when every output was written to the destination
+	ReasonSynced = "Synced"
+	// ReasonSourceNotFound is set when a source resource can't be read
+	ReasonSourceNotFound = "SourceNotFound"
+	// ReasonRequiredConditionsNotMet is set while a source doesn't meet the required status conditions
+	ReasonRequiredConditionsNotMet = "RequiredConditionsNotMet"
+	// ReasonQueryFailed is set when an output can't be resolved
+	ReasonQueryFailed = "QueryFailed"
+	// ReasonDestinationWriteFailed is set when the destination can't be written
+	ReasonDestinationWriteFailed = "DestinationWriteFailed"
+	// ReasonUpgraded is set on conditions written before they carried a reason
+	ReasonUpgraded = "Upgraded"
+	// ReasonDryRun is set when the outputs were resolved into the preview without being written
+	ReasonDryRun = "DryRun"
+	// ReasonReferenceNotPermitted is set when no FieldExportGrant allows a source or the destination
+	// in another namespace
+	ReasonReferenceNotPermitted = "ReferenceNotPermitted"
+)
+
+// OutputFallback is an output whose path resolved to null in the last reconciliation
+type OutputFallback struct {
+	Key string `json:"key"`
+	// Policy is the null policy that was applied, Empty, Skip or Default
+	Policy NullPolicy `json:"policy"`
+}
+
+// OutputState is the state of an output in the last reconciliation
+// +kubebuilder:validation:Enum=Synced;Pending;Missing;Error
+type OutputState string
+
+const (
+	// OutputSynced is an output whose value was resolved and written to the destination
+	OutputSynced OutputState = "Synced"
+	// OutputPending is an output whose value was resolved but not written, in a dry run or when
+	// writing the destination failed
+	OutputPending OutputState = "Pending"
+	// OutputMissing is an output whose path resolved to null and that fell back
+	OutputMissing OutputState = "Missing"
+	// OutputError is an output whose value could not be resolved
+	OutputError OutputState = "Error"
+)
+
+// OutputStatus is the observed state of an output
+type OutputStatus struct {
+	Key   string      `json:"key"`
+	State OutputState `json:"state"`
+	// LastError is the error resolving the output, if any
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+	// Hash is the HMAC-SHA256 of the value last written to the destination keyed by the UID of
+	// the export, the value itself is not recorded as it may be secret
+	// +optional
+	Hash string `json:"hash,omitempty"`
+	// LastChanged is the time the value in the destination last changed
+	// +optional
+	LastChanged *metav1.Time `json:"lastChanged,omitempty"`
+}
+
+// PreviewEntry is a value a dry run would write to the destination
+type PreviewEntry struct {
+	Key string `json:"key"`
+	// Value is the value that would be written, it is left out for Secret destinations
+	// +optional
+	Value string `json:"value,omitempty"`
+	// Hash is the HMAC-SHA256 of the value keyed by the UID of the export
+	// +optional
+	Hash string `json:"hash,omitempty"`
+	// Error is the error resolving the output, if any
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// ResourceFieldExportStatus defines the observed state of ResourceFieldExport
+type ResourceFieldExportStatus struct {
+	// ObservedGeneration is the generation of the spec the status was computed for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastSyncTime is the last time a change was synced to the destination
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// Conditions are the standard conditions of the export, Ready reports whether the outputs
+	// were written to the destination
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ExportedKeys are the destination keys last written by this export. Keys listed here
+	// that are no longer declared in outputs are removed from the destination.
+	// +optional
+	ExportedKeys []string `json:"exportedKeys,omitempty"`
+	// ExportedTo is the destination the exported keys were written to. When `to` refers to
+	// another destination the keys are removed from this one.
+	// +optional
+	ExportedTo *ExportedDestination `json:"exportedTo,omitempty"`
+	// Fallbacks are the outputs that were written empty, with their default or left out
+	// because their path resolved to null
+	// +optional
+	// +listType=map
+	// +listMapKey=key
+	Fallbacks []OutputFallback `json:"fallbacks,omitempty"`
+	// Outputs is the state of every output
+	// +optional
+	// +listType=map
+	// +listMapKey=key
+	Outputs []OutputStatus `json:"outputs,omitempty"`
+	// Preview is what the outputs would write to the destination, it is only set for dry runs
+	// +optional
+	// +listType=map
+	// +listMapKey=key
+	Preview []PreviewEntry `json:"preview,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=rfe,categories=field-exporter
+//+kubebuilder:printcolumn:name="Source Kind",type=string,JSONPath=`.spec.from.kind`
+//+kubebuilder:printcolumn:name="Source",type=string,JSONPath=`.spec.from.name`
+//+kubebuilder:printcolumn:name="Sources",type=string,JSONPath=`.spec.sources[*].name`,priority=1
+//+kubebuilder:printcolumn:name="Destination Type",type=string,JSONPath=`.spec.to.type`
+//+kubebuilder:printcolumn:name="Destination",type=string,JSONPath=`.spec.to.name`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+//+kubebuilder:printcolumn:name="Last Sync",type=date,JSONPath=`.status.lastSyncTime`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+//+kubebuilder:storageversion
+
+// ResourceFieldExport is the Schema for the resourcefieldexports API
+type ResourceFieldExport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ResourceFieldExportSpec   `json:"spec,omitempty"`
+	Status ResourceFieldExportStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ResourceFieldExportList contains a list of ResourceFieldExport
+type ResourceFieldExportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ResourceFieldExport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ResourceFieldExport{}, &ResourceFieldExportList{})
+}
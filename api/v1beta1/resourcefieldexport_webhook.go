@@ -0,0 +1,330 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/itchyny/gojq"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/deliveryhero/field-exporter/internal/render"
+	"github.com/deliveryhero/field-exporter/internal/resourcemanager"
+)
+
+// log is for logging in this package.
+var (
+	resourcefieldexportlog = logf.Log.WithName("resourcefieldexport-resource")
+	resourceValidator      *resourcemanager.ResourceManager
+	// resourceMapper tells cluster scoped kinds from namespaced ones
+	resourceMapper meta.RESTMapper
+	// strictPathValidation rejects paths to fields the source schema doesn't define instead of warning
+	strictPathValidation bool
+	// fieldKeyPattern matches the keys of output fields, it mirrors the pattern of the CRD
+	fieldKeyPattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+)
+
+// pathCheckTimeout bounds fetching the schemas of the sources within an admission request, paths
+// whose schema isn't available in time are not checked
+const pathCheckTimeout = 3 * time.Second
+
+// SetupWebhookWithManager will setup the manager to manage the webhooks
+func (r *ResourceFieldExport) SetupWebhookWithManager(mgr ctrl.Manager, validator *resourcemanager.ResourceManager, strictPaths bool) error {
+	resourceValidator = validator
+	resourceMapper = mgr.GetRESTMapper()
+	strictPathValidation = strictPaths
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&resourceFieldExportValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-gdp-deliveryhero-io-v1beta1-resourcefieldexport,mutating=false,failurePolicy=fail,sideEffects=None,groups=gdp.deliveryhero.io,resources=resourcefieldexports,verbs=create;update,versions=v1beta1,name=vresourcefieldexport.kb.io,admissionReviewVersions=v1
+
+// resourceFieldExportValidator validates ResourceFieldExports within the context of the admission
+// request, so fetching schemas is cancelled together with the request
+type resourceFieldExportValidator struct{}
+
+var _ webhook.CustomValidator = &resourceFieldExportValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *resourceFieldExportValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	r, ok := obj.(*ResourceFieldExport)
+	if !ok {
+		return nil, fmt.Errorf("expected a ResourceFieldExport but got %T", obj)
+	}
+	resourcefieldexportlog.Info("validate create", "name", r.Name, "namespace", r.Namespace)
+	return r.validate(ctx)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type.
+// Updates of a deleted export or of its metadata only are admitted without validation, so the
+// finalizer can be removed after the source kind is no longer supported.
+func (v *resourceFieldExportValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	r, ok := newObj.(*ResourceFieldExport)
+	if !ok {
+		return nil, fmt.Errorf("expected a ResourceFieldExport but got %T", newObj)
+	}
+	old, ok := oldObj.(*ResourceFieldExport)
+	if !ok {
+		return nil, fmt.Errorf("expected a ResourceFieldExport but got %T", oldObj)
+	}
+	if !r.DeletionTimestamp.IsZero() || equality.Semantic.DeepEqual(old.Spec, r.Spec) {
+		return nil, nil
+	}
+	resourcefieldexportlog.Info("validate update", "name", r.Name, "namespace", r.Namespace)
+	return r.validate(ctx)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *resourceFieldExportValidator) ValidateDelete(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	if r, ok := obj.(*ResourceFieldExport); ok {
+		resourcefieldexportlog.Info("validate delete", "name", r.Name, "namespace", r.Namespace)
+	}
+	return nil, nil
+}
+
+func (r *ResourceFieldExport) validate(ctx context.Context) (admission.Warnings, error) {
+	specPath := field.NewPath("spec")
+	var errs field.ErrorList
+	switch {
+	case r.Spec.From == nil && len(r.Spec.Sources) == 0:
+		errs = append(errs, field.Required(specPath, "exactly one of from or sources must be set"))
+	case r.Spec.From != nil && len(r.Spec.Sources) > 0:
+		errs = append(errs, field.Forbidden(specPath.Child("sources"), "exactly one of from or sources must be set"))
+	}
+	if r.Spec.From != nil {
+		errs = append(errs, validateResource(*r.Spec.From, specPath.Child("from"))...)
+	}
+	aliases := make(map[string]struct{}, len(r.Spec.Sources))
+	for i, s := range r.Spec.Sources {
+		sourcePath := specPath.Child("sources").Index(i)
+		if _, ok := aliases[s.Alias]; ok {
+			errs = append(errs, field.Duplicate(sourcePath.Child("alias"), s.Alias))
+		}
+		aliases[s.Alias] = struct{}{}
+		errs = append(errs, validateResource(s.ResourceRef, sourcePath)...)
+	}
+	errs = append(errs, validateOutputs(r.Spec.Outputs, specPath.Child("outputs"))...)
+	if len(errs) > 0 {
+		return nil, apierrors.NewInvalid(GroupVersion.WithKind("ResourceFieldExport").GroupKind(), r.Name, errs)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pathCheckTimeout)
+	defer cancel()
+	pathErrs := r.checkPaths(ctx, specPath.Child("outputs"))
+	if strictPathValidation && len(pathErrs) > 0 {
+		return nil, apierrors.NewInvalid(GroupVersion.WithKind("ResourceFieldExport").GroupKind(), r.Name, pathErrs)
+	}
+	var warnings admission.Warnings
+	for _, err := range pathErrs {
+		warnings = append(warnings, err.Error())
+	}
+	return warnings, nil
+}
+
+// checkPaths compares the simple field paths of the outputs with the schemas of the sources. Sources
+// whose schema can't be fetched are not checked.
+func (r *ResourceFieldExport) checkPaths(ctx context.Context, fldPath *field.Path) field.ErrorList {
+	var root *apiextensionsv1.JSONSchemaProps
+	if r.Spec.From != nil {
+		root = sourceSchema(ctx, *r.Spec.From)
+	} else {
+		// the input of multiple sources is an object keyed by their aliases
+		root = &apiextensionsv1.JSONSchemaProps{Type: "object", Properties: map[string]apiextensionsv1.JSONSchemaProps{}}
+		for _, s := range r.Spec.Sources {
+			props := sourceSchema(ctx, s.ResourceRef)
+			if props == nil {
+				props = &apiextensionsv1.JSONSchemaProps{XPreserveUnknownFields: ptr.To(true)}
+			}
+			root.Properties[s.Alias] = *props
+		}
+	}
+	if root == nil {
+		return nil
+	}
+
+	var errs field.ErrorList
+	check := func(query string, queryPath *field.Path) {
+		steps, ok := resourcemanager.FieldPath(query)
+		if !ok {
+			return
+		}
+		if err := resourcemanager.CheckPath(root, steps); err != nil {
+			errs = append(errs, field.Invalid(queryPath, query, err.Error()))
+		}
+	}
+	for i, o := range r.Spec.Outputs {
+		if o.Path != "" {
+			check(o.Path, fldPath.Index(i).Child("path"))
+		}
+		for j, f := range o.Fields {
+			check(f.Path, fldPath.Index(i).Child("fields").Index(j).Child("path"))
+		}
+	}
+	return errs
+}
+
+// sourceSchema returns the schema of a source or nil when it isn't available.
+func sourceSchema(ctx context.Context, ref ResourceRef) *apiextensionsv1.JSONSchemaProps {
+	props, err := resourceValidator.Schema(ctx, ref.APIVersion, ref.Kind)
+	if err != nil {
+		resourcefieldexportlog.Error(err, "failed to get schema, paths are not checked", "source", fmt.Sprintf("%s/%s", ref.APIVersion, ref.Kind))
+		return nil
+	}
+	return props
+}
+
+// validateResource checks that the resource belongs to a supported group and is served by the cluster,
+// and that cluster scoped resources are referred to without a namespace.
+func validateResource(ref ResourceRef, fldPath *field.Path) field.ErrorList {
+	if err := resourceValidator.Validate(ref.APIVersion, ref.Kind); err != nil {
+		return field.ErrorList{field.Invalid(fldPath, ref.APIVersion+"/"+ref.Kind, err.Error())}
+	}
+	if ref.Namespace != "" && clusterScoped(ref) {
+		return field.ErrorList{field.Forbidden(fldPath.Child("namespace"), fmt.Sprintf("%s is cluster scoped and has no namespace", ref.Kind))}
+	}
+	return nil
+}
+
+// clusterScoped reports whether the kind of the resource is cluster scoped, kinds the mapper
+// can't map are taken to be namespaced.
+func clusterScoped(ref ResourceRef) bool {
+	if resourceMapper == nil {
+		return false
+	}
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return false
+	}
+	mapping, err := resourceMapper.RESTMapping(schema.GroupKind{Group: gv.Group, Kind: ref.Kind}, gv.Version)
+	if err != nil {
+		return false
+	}
+	return mapping.Scope.Name() == meta.RESTScopeNameRoot
+}
+
+// validateOutputs checks the keys of the outputs and compiles their queries and templates.
+func validateOutputs(outputs []Output, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(outputs) == 0 {
+		errs = append(errs, field.Required(fldPath, "at least one output must be set"))
+	}
+	keys := make(map[string]struct{}, len(outputs))
+	for i, o := range outputs {
+		outputPath := fldPath.Index(i)
+		for _, msg := range validation.IsConfigMapKey(o.Key) {
+			errs = append(errs, field.Invalid(outputPath.Child("key"), o.Key, msg))
+		}
+		if _, ok := keys[o.Key]; ok {
+			errs = append(errs, field.Duplicate(outputPath.Child("key"), o.Key))
+		}
+		keys[o.Key] = struct{}{}
+
+		if set := btoi(o.Path != "") + btoi(o.Template != "") + btoi(len(o.Fields) > 0); set != 1 {
+			errs = append(errs, field.Invalid(outputPath, o.Key, "exactly one of path, template or fields must be set"))
+		}
+		if len(o.Fields) > 0 && o.Format == "" {
+			errs = append(errs, field.Required(outputPath.Child("format"), "fields require a format"))
+		}
+		if o.Template != "" && o.Format != "" {
+			errs = append(errs, field.Forbidden(outputPath.Child("format"), "template can't be combined with a format"))
+		}
+		if o.Optional && o.Default != nil {
+			errs = append(errs, field.Forbidden(outputPath.Child("default"), "optional can't be combined with a default"))
+		}
+		if o.Optional && o.OnNull != "" && o.OnNull != NullSkip {
+			errs = append(errs, field.Forbidden(outputPath.Child("onNull"), "optional can't be combined with onNull other than Skip"))
+		}
+		if o.OnNull == NullDefault && o.Default == nil {
+			errs = append(errs, field.Required(outputPath.Child("default"), "onNull Default requires a default"))
+		}
+		if o.Path == "" {
+			// only a path resolves to null, templates and fields fail on a missing field
+			if o.Optional {
+				errs = append(errs, field.Forbidden(outputPath.Child("optional"), "optional requires a path"))
+			}
+			if o.OnNull != "" {
+				errs = append(errs, field.Forbidden(outputPath.Child("onNull"), "onNull requires a path"))
+			}
+			if o.Default != nil {
+				errs = append(errs, field.Forbidden(outputPath.Child("default"), "default requires a path"))
+			}
+		}
+
+		if o.Path != "" {
+			if err := compileQuery(o.Path); err != nil {
+				errs = append(errs, field.Invalid(outputPath.Child("path"), o.Path, err.Error()))
+			}
+		}
+		fieldKeys := make(map[string]struct{}, len(o.Fields))
+		for j, f := range o.Fields {
+			fieldPath := outputPath.Child("fields").Index(j)
+			switch {
+			case !fieldKeyPattern.MatchString(f.Key):
+				errs = append(errs, field.Invalid(fieldPath.Child("key"), f.Key, "must consist of alphanumeric characters, '-', '_' or '.'"))
+			case o.Format == Dotenv && !render.IsDotenvKey(f.Key):
+				errs = append(errs, field.Invalid(fieldPath.Child("key"), f.Key, "must be a valid variable name, consisting of alphanumeric characters or '_' and not starting with a digit"))
+			}
+			if _, ok := fieldKeys[f.Key]; ok {
+				errs = append(errs, field.Duplicate(fieldPath.Child("key"), f.Key))
+			}
+			fieldKeys[f.Key] = struct{}{}
+			if err := compileQuery(f.Path); err != nil {
+				errs = append(errs, field.Invalid(fieldPath.Child("path"), f.Path, err.Error()))
+			}
+		}
+		if o.Template != "" {
+			if _, err := render.ParseTemplate(o.Template); err != nil {
+				errs = append(errs, field.Invalid(outputPath.Child("template"), o.Template, err.Error()))
+			}
+		}
+	}
+	return errs
+}
+
+// compileQuery parses and compiles a jq query, compiling catches unknown functions and variables.
+func compileQuery(query string) error {
+	parsed, err := gojq.Parse(query)
+	if err != nil {
+		return err
+	}
+	_, err = gojq.Compile(parsed)
+	return err
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,281 @@
+package v1beta1
+
+import (
+	. "github.com/onsi/ginkgo/v2" //nolint:revive
+	. "github.com/onsi/gomega"    //nolint:revive
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("ResourceFieldExport Webhook", func() {
+	_ = Context("on create", func() {
+		var rfe *ResourceFieldExport
+		BeforeEach(func() {
+			rfe = &ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "invalid-source",
+					Namespace: "default",
+				},
+				Spec: ResourceFieldExportSpec{
+					From: &ResourceRef{
+						APIVersion: "redis.cnrm.cloud.google.com/v1beta1",
+						Kind:       "RedisInstance",
+						Name:       "sensitive-secret",
+					},
+					To: DestinationRef{
+						Type: ConfigMap,
+						Name: "compromised",
+					},
+					RequiredFields: &RequiredFields{StatusConditions: []StatusCondition{
+						{
+							Type:   "Ready",
+							Status: "True",
+						},
+					}},
+					Outputs: []Output{
+						{
+							Key:  "ip",
+							Path: ".status.ip",
+						},
+					},
+				},
+			}
+		})
+
+		_ = When("source resource is valid", func() {
+			It("succeeds", func() {
+				Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+			})
+		})
+
+		_ = When("source resource is invalid", func() {
+			It("fails", func() {
+				rfe.Spec.From = &ResourceRef{
+					APIVersion: "v1",
+					Kind:       "Secret",
+					Name:       "super-sensitive",
+				}
+				Expect(k8sClient.Create(ctx, rfe)).Should(MatchError(ContainSubstring("unsupported GroupVersion v1")))
+			})
+		})
+
+		_ = When("source resource is unknown", func() {
+			It("fails", func() {
+				rfe.Spec.From.Kind = "RedisCluster"
+				Expect(k8sClient.Create(ctx, rfe)).Should(MatchError(ContainSubstring("unsupported resource: redis.cnrm.cloud.google.com/v1beta1, Kind=RedisCluster")))
+			})
+		})
+
+		_ = When("source resource is a Crossplane managed resource", func() {
+			It("succeeds", func() {
+				rfe.Spec.From = &ResourceRef{
+					APIVersion: "s3.aws.upbound.io/v1beta1",
+					Kind:       "Bucket",
+					Name:       "assets",
+				}
+				rfe.Spec.RequiredFields = nil
+				rfe.Spec.Outputs[0].Path = ".status.atProvider.bucketRegionalDomainName"
+				Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+			})
+		})
+
+		_ = When("source resource is an Azure Service Operator resource", func() {
+			It("succeeds", func() {
+				rfe.Spec.From = &ResourceRef{
+					APIVersion: "dbforpostgresql.azure.com/v1api20221201",
+					Kind:       "FlexibleServer",
+					Name:       "db",
+				}
+				rfe.Spec.Outputs[0].Path = ".status.fullyQualifiedDomainName"
+				Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+			})
+		})
+
+		_ = When("sources are valid", func() {
+			It("succeeds", func() {
+				rfe.Spec.Sources = []Source{
+					{Alias: "cache", ResourceRef: *rfe.Spec.From},
+					{Alias: "db", ResourceRef: ResourceRef{
+						APIVersion: "rds.services.k8s.aws/v1alpha1",
+						Kind:       "DBCluster",
+						Name:       "db",
+					}},
+				}
+				rfe.Spec.From = nil
+				Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+			})
+		})
+
+		_ = When("both from and sources are set", func() {
+			It("fails", func() {
+				rfe.Spec.Sources = []Source{{Alias: "cache", ResourceRef: *rfe.Spec.From}}
+				Expect(k8sClient.Create(ctx, rfe)).Should(MatchError(ContainSubstring("exactly one of from or sources must be set")))
+			})
+		})
+
+		_ = When("a source is unknown", func() {
+			It("fails", func() {
+				rfe.Spec.Sources = []Source{{Alias: "cache", ResourceRef: ResourceRef{
+					APIVersion: "redis.cnrm.cloud.google.com/v1beta1",
+					Kind:       "RedisCluster",
+					Name:       "cache",
+				}}}
+				rfe.Spec.From = nil
+				Expect(k8sClient.Create(ctx, rfe)).Should(MatchError(ContainSubstring("unsupported resource: redis.cnrm.cloud.google.com/v1beta1, Kind=RedisCluster")))
+			})
+		})
+
+		_ = When("output template is invalid", func() {
+			It("fails", func() {
+				rfe.Spec.Outputs[0] = Output{Key: "url", Template: "redis://{{ .status.host "}
+				Expect(k8sClient.Create(ctx, rfe)).Should(MatchError(ContainSubstring("spec.outputs[0].template: Invalid value")))
+			})
+		})
+
+		_ = When("output template is valid", func() {
+			It("succeeds", func() {
+				rfe.Spec.Outputs[0] = Output{Key: "url", Template: "redis://{{ .status.host }}:{{ .status.port }}"}
+				Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+			})
+		})
+
+		_ = When("output fields are formatted", func() {
+			It("succeeds", func() {
+				rfe.Spec.Outputs[0] = Output{Key: "application.yaml", Format: YAML, Fields: []Field{
+					{Key: "host", Path: ".status.host"},
+					{Key: "port", Path: ".status.port"},
+				}}
+				Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+			})
+		})
+
+		_ = When("dotenv output fields are not variable names", func() {
+			It("fails", func() {
+				rfe.Spec.Outputs[0] = Output{Key: ".env", Format: Dotenv, Fields: []Field{
+					{Key: "redis.host", Path: ".status.host"},
+				}}
+				Expect(k8sClient.Create(ctx, rfe)).Should(MatchError(ContainSubstring("must be a valid variable name")))
+			})
+		})
+
+		_ = When("output fields have no format", func() {
+			It("fails", func() {
+				rfe.Spec.Outputs[0] = Output{Key: "application.yaml", Fields: []Field{
+					{Key: "host", Path: ".status.host"},
+				}}
+				Expect(k8sClient.Create(ctx, rfe)).Should(MatchError(ContainSubstring("fields require a format")))
+			})
+		})
+
+		_ = When("optional output has a default", func() {
+			It("fails", func() {
+				rfe.Spec.Outputs[0].Optional = true
+				rfe.Spec.Outputs[0].Default = ptr.To("6379")
+				Expect(k8sClient.Create(ctx, rfe)).Should(MatchError(ContainSubstring("optional can't be combined with a default")))
+			})
+		})
+
+		_ = When("output has a default", func() {
+			It("succeeds", func() {
+				rfe.Spec.Outputs[0].Default = ptr.To("6379")
+				Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+			})
+		})
+
+		_ = When("output key is invalid", func() {
+			It("fails", func() {
+				rfe.Spec.Outputs[0].Key = "**&&&&"
+				Expect(k8sClient.Create(ctx, rfe)).Should(MatchError(ContainSubstring(`spec.outputs[0].key: Invalid value: "**&&&&"`)))
+			})
+		})
+
+		_ = When("output path is invalid", func() {
+			It("fails", func() {
+				rfe.Spec.Outputs[0].Path = ".status.ip |"
+				Expect(k8sClient.Create(ctx, rfe)).Should(MatchError(ContainSubstring(`spec.outputs[0].path: Invalid value: ".status.ip |"`)))
+			})
+		})
+
+		_ = When("output path calls an unknown function", func() {
+			It("fails", func() {
+				rfe.Spec.Outputs[0].Path = ".status.ip | tostrng"
+				Expect(k8sClient.Create(ctx, rfe)).Should(MatchError(ContainSubstring("function not defined: tostrng/0")))
+			})
+		})
+
+		_ = When("output keys are duplicated", func() {
+			It("fails", func() {
+				rfe.Spec.Outputs = append(rfe.Spec.Outputs, Output{Key: "ip", Path: ".status.host"})
+				Expect(k8sClient.Create(ctx, rfe)).Should(MatchError(ContainSubstring(`spec.outputs[1].key: Duplicate value: "ip"`)))
+			})
+		})
+
+		_ = When("outputs are empty", func() {
+			It("fails", func() {
+				rfe.Spec.Outputs = nil
+				Expect(k8sClient.Create(ctx, rfe)).ShouldNot(Succeed())
+			})
+		})
+
+		_ = When("several outputs are invalid", func() {
+			It("reports all of them", func() {
+				rfe.Spec.Outputs = []Output{
+					{Key: "host", Path: ".status.host |"},
+					{Key: "port", Path: ".status.port | tostrng"},
+				}
+				err := k8sClient.Create(ctx, rfe)
+				Expect(err).Should(MatchError(ContainSubstring("spec.outputs[0].path")))
+				Expect(err).Should(MatchError(ContainSubstring("spec.outputs[1].path")))
+			})
+		})
+	})
+
+	_ = Context("on update", func() {
+		widget := schema.GroupVersionKind{Group: "widgets.example.com", Version: "v1", Kind: "Widget"}
+
+		_ = When("the source kind is no longer supported", func() {
+			It("admits removing the finalizer of a deleted export", func() {
+				resourceValidator.Register("widgets", map[schema.GroupVersionKind]string{widget: "widgets"})
+				DeferCleanup(func() { resourceValidator.Unregister("widgets") })
+				rfe := &ResourceFieldExport{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       "unregistered-source",
+						Namespace:  "default",
+						Finalizers: []string{"gdp.deliveryhero.io/finalizer"},
+					},
+					Spec: ResourceFieldExportSpec{
+						From: &ResourceRef{
+							APIVersion: widget.GroupVersion().String(),
+							Kind:       widget.Kind,
+							Name:       "widget",
+						},
+						To:             DestinationRef{Type: ConfigMap, Name: "widget"},
+						DeletionPolicy: Delete,
+						Outputs:        []Output{{Key: "color", Path: ".status.color"}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+
+				// the ExportableResource registering the kind is deleted
+				resourceValidator.Unregister("widgets")
+
+				// metadata can still be changed, the spec can't
+				rfe.Labels = map[string]string{"team": "payments"}
+				Expect(k8sClient.Update(ctx, rfe)).Should(Succeed())
+				changed := rfe.DeepCopy()
+				changed.Spec.Outputs[0].Key = "colour"
+				Expect(k8sClient.Update(ctx, changed)).Should(MatchError(ContainSubstring("unsupported GroupVersion widgets.example.com/v1")))
+
+				Expect(k8sClient.Delete(ctx, rfe)).Should(Succeed())
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(rfe), rfe)).Should(Succeed())
+				Expect(rfe.DeletionTimestamp).ShouldNot(BeNil())
+				rfe.Finalizers = nil
+				Expect(k8sClient.Update(ctx, rfe)).Should(Succeed())
+				Expect(apierrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(rfe), rfe))).Should(BeTrue())
+			})
+		})
+	})
+})
@@ -0,0 +1,358 @@
+package v1beta1
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+
+	"github.com/deliveryhero/field-exporter/internal/resourcemanager"
+)
+
+func TestValidateOutputs(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		outputs      []Output
+		expectErrors []string
+	}{
+		{
+			name: "valid outputs",
+			outputs: []Output{
+				{Key: "host", Path: ".status.host"},
+				{Key: "redis.url", Template: "redis://{{ .status.host }}"},
+				{Key: "application.yaml", Format: YAML, Fields: []Field{{Key: "port", Path: ".status.port | tostring"}}},
+				{Key: "READ_PORT", Path: ".status.readEndpointPort", Default: ptr.To("6379")},
+			},
+		},
+		{
+			name:         "no outputs",
+			expectErrors: []string{"spec.outputs: Required value: at least one output must be set"},
+		},
+		{
+			name: "invalid keys",
+			outputs: []Output{
+				{Key: "db host", Path: ".status.host"},
+				{Key: "..", Path: ".status.host"},
+			},
+			expectErrors: []string{
+				`spec.outputs[0].key: Invalid value: "db host": a valid config key must consist of alphanumeric characters, '-', '_' or '.' (e.g. 'key.name',  or 'KEY_NAME',  or 'key-name', regex used for validation is '[-._a-zA-Z0-9]+')`,
+				`spec.outputs[1].key: Invalid value: "..": must not be '..'`,
+			},
+		},
+		{
+			name: "duplicate keys",
+			outputs: []Output{
+				{Key: "host", Path: ".status.host"},
+				{Key: "port", Path: ".status.port"},
+				{Key: "host", Path: ".status.ipAddress"},
+			},
+			expectErrors: []string{`spec.outputs[2].key: Duplicate value: "host"`},
+		},
+		{
+			name: "invalid paths",
+			outputs: []Output{
+				{Key: "host", Path: ".status.host |"},
+				{Key: "port", Path: ".status.port | tostrng"},
+				{Key: "url", Path: "$url"},
+				{Key: ".env", Format: Dotenv, Fields: []Field{{Key: "HOST", Path: ".status.[host"}}},
+			},
+			expectErrors: []string{
+				`spec.outputs[0].path: Invalid value: ".status.host |": unexpected EOF`,
+				`spec.outputs[1].path: Invalid value: ".status.port | tostrng": function not defined: tostrng/0`,
+				`spec.outputs[2].path: Invalid value: "$url": variable not defined: $url`,
+				`spec.outputs[3].fields[0].path: Invalid value: ".status.[host": unexpected EOF`,
+			},
+		},
+		{
+			name: "invalid field keys",
+			outputs: []Output{
+				{Key: "application.yaml", Format: YAML, Fields: []Field{
+					{Key: "db host", Path: ".status.host"},
+					{Key: "port", Path: ".status.port"},
+					{Key: "port", Path: ".status.readEndpointPort"},
+				}},
+				{Key: ".env", Format: Dotenv, Fields: []Field{
+					{Key: "DB_HOST", Path: ".status.host"},
+					{Key: "db.port", Path: ".status.port"},
+					{Key: "1PORT", Path: ".status.port"},
+				}},
+			},
+			expectErrors: []string{
+				`spec.outputs[0].fields[0].key: Invalid value: "db host": must consist of alphanumeric characters, '-', '_' or '.'`,
+				`spec.outputs[0].fields[2].key: Duplicate value: "port"`,
+				`spec.outputs[1].fields[1].key: Invalid value: "db.port": must be a valid variable name, consisting of alphanumeric characters or '_' and not starting with a digit`,
+				`spec.outputs[1].fields[2].key: Invalid value: "1PORT": must be a valid variable name, consisting of alphanumeric characters or '_' and not starting with a digit`,
+			},
+		},
+		{
+			name: "invalid template",
+			outputs: []Output{
+				{Key: "url", Template: "redis://{{ .status.host "},
+			},
+			expectErrors: []string{`spec.outputs[0].template: Invalid value: "redis://{{ .status.host ": template: output:1: unclosed action`},
+		},
+		{
+			name: "conflicting settings",
+			outputs: []Output{
+				{Key: "host", Path: ".status.host", Template: "{{ .status.host }}"},
+				{Key: "port", Path: ".status.port", Optional: true, Default: ptr.To("6379")},
+			},
+			expectErrors: []string{
+				`spec.outputs[0]: Invalid value: "host": exactly one of path, template or fields must be set`,
+				`spec.outputs[1].default: Forbidden: optional can't be combined with a default`,
+			},
+		},
+		{
+			name: "null handling without a path",
+			outputs: []Output{
+				{Key: "url", Template: "redis://{{ .status.host }}", Optional: true},
+				{Key: "application.yaml", Format: YAML, Fields: []Field{{Key: "port", Path: ".status.port"}}, OnNull: NullEmpty},
+				{Key: "host", Template: "{{ .status.host }}", Default: ptr.To("localhost")},
+			},
+			expectErrors: []string{
+				`spec.outputs[0].optional: Forbidden: optional requires a path`,
+				`spec.outputs[1].onNull: Forbidden: onNull requires a path`,
+				`spec.outputs[2].default: Forbidden: default requires a path`,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validateOutputs(tc.outputs, field.NewPath("spec", "outputs"))
+			messages := make([]string, 0, len(errs))
+			for _, err := range errs {
+				messages = append(messages, err.Error())
+			}
+			require.ElementsMatch(t, tc.expectErrors, messages)
+		})
+	}
+}
+
+func TestValidateResource(t *testing.T) {
+	rm, err := resourcemanager.NewResourceManager(&testPreferredResources{resources: map[string]metav1.APIResource{
+		"redis.cnrm.cloud.google.com/v1beta1": {Name: "redisinstances", Kind: "RedisInstance", Namespaced: true},
+		"s3.aws.upbound.io/v1beta1":           {Name: "buckets", Kind: "Bucket"},
+	}}, resourcemanager.DefaultGroups)
+	require.NoError(t, err)
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "redis.cnrm.cloud.google.com", Version: "v1beta1", Kind: "RedisInstance"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "s3.aws.upbound.io", Version: "v1beta1", Kind: "Bucket"}, meta.RESTScopeRoot)
+	resourceValidator, resourceMapper = rm, mapper
+	t.Cleanup(func() {
+		resourceValidator, resourceMapper = nil, nil
+	})
+
+	for _, tc := range []struct {
+		name         string
+		ref          ResourceRef
+		expectErrors []string
+	}{
+		{
+			name: "namespaced resource of another namespace",
+			ref:  ResourceRef{APIVersion: "redis.cnrm.cloud.google.com/v1beta1", Kind: "RedisInstance", Name: "cache", Namespace: "platform"},
+		},
+		{
+			name: "cluster scoped resource",
+			ref:  ResourceRef{APIVersion: "s3.aws.upbound.io/v1beta1", Kind: "Bucket", Name: "assets"},
+		},
+		{
+			name:         "cluster scoped resource with a namespace",
+			ref:          ResourceRef{APIVersion: "s3.aws.upbound.io/v1beta1", Kind: "Bucket", Name: "assets", Namespace: "platform"},
+			expectErrors: []string{"spec.from.namespace: Forbidden: Bucket is cluster scoped and has no namespace"},
+		},
+		{
+			name:         "unsupported resource",
+			ref:          ResourceRef{APIVersion: "redis.cnrm.cloud.google.com/v1", Kind: "RedisInstance", Name: "cache", Namespace: "platform"},
+			expectErrors: []string{`spec.from: Invalid value: "redis.cnrm.cloud.google.com/v1/RedisInstance": unsupported resource: redis.cnrm.cloud.google.com/v1, Kind=RedisInstance`},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validateResource(tc.ref, field.NewPath("spec", "from"))
+			messages := make([]string, 0, len(errs))
+			for _, err := range errs {
+				messages = append(messages, err.Error())
+			}
+			require.ElementsMatch(t, tc.expectErrors, messages)
+		})
+	}
+}
+
+func TestCheckPaths(t *testing.T) {
+	redis := ResourceRef{APIVersion: "redis.cnrm.cloud.google.com/v1beta1", Kind: "RedisInstance", Name: "cache"}
+	sql := ResourceRef{APIVersion: "sql.cnrm.cloud.google.com/v1beta1", Kind: "SQLInstance", Name: "db"}
+	rm, err := resourcemanager.NewResourceManager(&testPreferredResources{resources: map[string]metav1.APIResource{
+		"redis.cnrm.cloud.google.com/v1beta1": {Name: "redisinstances", Kind: "RedisInstance"},
+		"sql.cnrm.cloud.google.com/v1beta1":   {Name: "sqlinstances", Kind: "SQLInstance"},
+		"s3.aws.upbound.io/v1beta1":           {Name: "buckets", Kind: "Bucket"},
+		"cache.azure.com/v1api20230801":       {Name: "redis", Kind: "Redis"},
+	}}, resourcemanager.DefaultGroups)
+	require.NoError(t, err)
+	// kinds outside the allowed groups are checked once registered with an ExportableResource
+	rm.Register("certificates", map[schema.GroupVersionKind]string{
+		{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}: "certificates",
+	})
+	resourceValidator = rm.WithSchemas(testCRDs{
+		"redisinstances.redis.cnrm.cloud.google.com": "../../hack/config-connector-crds/redisinstance.yaml",
+		"buckets.s3.aws.upbound.io":                  "../../hack/crossplane-crds/s3.aws.upbound.io_buckets.yaml",
+		"redis.cache.azure.com":                      "../../hack/aso-crds/cache.azure.com_redis.yaml",
+		"certificates.cert-manager.io":               "../../hack/cert-manager-crds/cert-manager.io_certificates.yaml",
+	})
+	t.Cleanup(func() {
+		resourceValidator = nil
+		strictPathValidation = false
+	})
+
+	for _, tc := range []struct {
+		name           string
+		spec           ResourceFieldExportSpec
+		expectWarnings []string
+	}{
+		{
+			name: "existing fields",
+			spec: ResourceFieldExportSpec{
+				From: &redis,
+				Outputs: []Output{
+					{Key: "host", Path: ".status.host"},
+					{Key: "project", Path: `.metadata.annotations["cnrm.cloud.google.com/project-id"]`},
+					{Key: "zone", Path: ".status.nodes[0].zone | ascii_upcase"},
+				},
+			},
+		},
+		{
+			name: "unknown fields",
+			spec: ResourceFieldExportSpec{
+				From: &redis,
+				Outputs: []Output{
+					{Key: "host", Path: ".status.hostt"},
+					{Key: "config.json", Format: JSON, Fields: []Field{{Key: "port", Path: ".status.prot"}}},
+				},
+			},
+			expectWarnings: []string{
+				`spec.outputs[0].path: Invalid value: ".status.hostt": field .status.hostt does not exist, did you mean .status.host?`,
+				`spec.outputs[1].fields[0].path: Invalid value: ".status.prot": field .status.prot does not exist, did you mean .status.port?`,
+			},
+		},
+		{
+			name: "sources",
+			spec: ResourceFieldExportSpec{
+				Sources: []Source{{Alias: "cache", ResourceRef: redis}, {Alias: "db", ResourceRef: sql}},
+				Outputs: []Output{
+					{Key: "host", Path: ".cache.status.hots"},
+					{Key: "db", Path: ".db.status.connectionName"},
+					{Key: "user", Path: ".dbb.spec.user"},
+				},
+			},
+			expectWarnings: []string{
+				`spec.outputs[0].path: Invalid value: ".cache.status.hots": field .cache.status.hots does not exist, did you mean .cache.status.host?`,
+				`spec.outputs[2].path: Invalid value: ".dbb.spec.user": field .dbb does not exist, did you mean .db?`,
+			},
+		},
+		{
+			name: "crossplane managed resource",
+			spec: ResourceFieldExportSpec{
+				From: &ResourceRef{APIVersion: "s3.aws.upbound.io/v1beta1", Kind: "Bucket", Name: "assets"},
+				Outputs: []Output{
+					{Key: "arn", Path: ".status.atProvider.arn"},
+					{Key: "domain", Path: ".status.atProvider.bucketDomainNam"},
+				},
+			},
+			expectWarnings: []string{
+				`spec.outputs[1].path: Invalid value: ".status.atProvider.bucketDomainNam": field .status.atProvider.bucketDomainNam does not exist, did you mean .status.atProvider.bucketDomainName?`,
+			},
+		},
+		{
+			name: "azure service operator resource",
+			spec: ResourceFieldExportSpec{
+				From: &ResourceRef{APIVersion: "cache.azure.com/v1api20230801", Kind: "Redis", Name: "cache"},
+				Outputs: []Output{
+					{Key: "host", Path: ".status.hostName"},
+					{Key: "port", Path: ".status.sslPort.number"},
+				},
+			},
+			expectWarnings: []string{
+				`spec.outputs[1].path: Invalid value: ".status.sslPort.number": field .status.sslPort is of type integer, not an object`,
+			},
+		},
+		{
+			name: "registered kind",
+			spec: ResourceFieldExportSpec{
+				From: &ResourceRef{APIVersion: "cert-manager.io/v1", Kind: "Certificate", Name: "tls"},
+				Outputs: []Output{
+					{Key: "secret", Path: ".spec.secretName"},
+					{Key: "expiry", Path: ".status.notAftr"},
+				},
+			},
+			expectWarnings: []string{
+				`spec.outputs[1].path: Invalid value: ".status.notAftr": field .status.notAftr does not exist, did you mean .status.notAfter?`,
+			},
+		},
+		{
+			name: "unavailable schema",
+			spec: ResourceFieldExportSpec{
+				From:    &sql,
+				Outputs: []Output{{Key: "name", Path: ".status.connectionNam"}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			export := &ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{Name: "export"},
+				Spec:       tc.spec,
+			}
+			export.Spec.To = DestinationRef{Type: ConfigMap, Name: "config"}
+
+			strictPathValidation = false
+			warnings, err := export.validate(context.Background())
+			require.NoError(t, err)
+			require.ElementsMatch(t, tc.expectWarnings, warnings)
+
+			strictPathValidation = true
+			warnings, err = export.validate(context.Background())
+			require.Empty(t, warnings)
+			if len(tc.expectWarnings) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, apierrors.IsInvalid(err))
+			for _, warning := range tc.expectWarnings {
+				require.ErrorContains(t, err, warning)
+			}
+		})
+	}
+}
+
+type testPreferredResources struct {
+	resources map[string]metav1.APIResource
+}
+
+func (r *testPreferredResources) ServerPreferredResources() ([]*metav1.APIResourceList, error) {
+	lists := make([]*metav1.APIResourceList, 0, len(r.resources))
+	for gv, resource := range r.resources {
+		lists = append(lists, &metav1.APIResourceList{GroupVersion: gv, APIResources: []metav1.APIResource{resource}})
+	}
+	return lists, nil
+}
+
+// testCRDs maps the names of CRDs to their manifests
+type testCRDs map[string]string
+
+func (c testCRDs) Get(_ context.Context, name string, _ metav1.GetOptions) (*apiextensionsv1.CustomResourceDefinition, error) {
+	file, ok := c[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(apiextensionsv1.Resource("customresourcedefinitions"), name)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	return crd, yaml.Unmarshal(data, crd)
+}
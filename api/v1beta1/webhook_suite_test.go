@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package v1alpha1
+package v1beta1
 
 import (
 	"context"
@@ -72,6 +72,8 @@ var _ = BeforeSuite(func() {
 			filepath.Join("..", "..", "config", "crd", "bases"),
 			filepath.Join("..", "..", "hack", "config-connector-crds"),
 			filepath.Join("..", "..", "hack", "ack-crds"),
+			filepath.Join("..", "..", "hack", "crossplane-crds"),
+			filepath.Join("..", "..", "hack", "aso-crds"),
 		},
 		ErrorIfCRDPathMissing: false,
 
@@ -111,7 +113,7 @@ var _ = BeforeSuite(func() {
 	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
 	Expect(err).NotTo(HaveOccurred())
 	Expect(discoveryClient).NotTo(BeNil())
-	resourceValidator, err := resourcemanager.NewResourceManager(discoveryClient)
+	resourceValidator, err := resourcemanager.NewResourceManager(discoveryClient, resourcemanager.DefaultGroups)
 	Expect(err).NotTo(HaveOccurred())
 	Expect(resourceValidator).NotTo(BeNil())
 
@@ -129,7 +131,7 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).NotTo(HaveOccurred())
 
-	err = (&ResourceFieldExport{}).SetupWebhookWithManager(mgr, resourceValidator)
+	err = (&ResourceFieldExport{}).SetupWebhookWithManager(mgr, resourceValidator, false)
 	Expect(err).NotTo(HaveOccurred())
 
 	//+kubebuilder:scaffold:webhook
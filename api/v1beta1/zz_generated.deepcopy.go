@@ -0,0 +1,588 @@
+//go:build !ignore_autogenerated
+
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DestinationRef) DeepCopyInto(out *DestinationRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DestinationRef.
+func (in *DestinationRef) DeepCopy() *DestinationRef {
+	if in == nil {
+		return nil
+	}
+	out := new(DestinationRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportableResource) DeepCopyInto(out *ExportableResource) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportableResource.
+func (in *ExportableResource) DeepCopy() *ExportableResource {
+	if in == nil {
+		return nil
+	}
+	out := new(ExportableResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ExportableResource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportableResourceList) DeepCopyInto(out *ExportableResourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ExportableResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportableResourceList.
+func (in *ExportableResourceList) DeepCopy() *ExportableResourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(ExportableResourceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ExportableResourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportableResourceSpec) DeepCopyInto(out *ExportableResourceSpec) {
+	*out = *in
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportableResourceSpec.
+func (in *ExportableResourceSpec) DeepCopy() *ExportableResourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExportableResourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportableResourceStatus) DeepCopyInto(out *ExportableResourceStatus) {
+	*out = *in
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]v1.PolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportableResourceStatus.
+func (in *ExportableResourceStatus) DeepCopy() *ExportableResourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ExportableResourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportedDestination) DeepCopyInto(out *ExportedDestination) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportedDestination.
+func (in *ExportedDestination) DeepCopy() *ExportedDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(ExportedDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Field) DeepCopyInto(out *Field) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Field.
+func (in *Field) DeepCopy() *Field {
+	if in == nil {
+		return nil
+	}
+	out := new(Field)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FieldExportGrant) DeepCopyInto(out *FieldExportGrant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldExportGrant.
+func (in *FieldExportGrant) DeepCopy() *FieldExportGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldExportGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FieldExportGrant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FieldExportGrantList) DeepCopyInto(out *FieldExportGrantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FieldExportGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldExportGrantList.
+func (in *FieldExportGrantList) DeepCopy() *FieldExportGrantList {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldExportGrantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FieldExportGrantList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FieldExportGrantSpec) DeepCopyInto(out *FieldExportGrantSpec) {
+	*out = *in
+	if in.From != nil {
+		in, out := &in.From, &out.From
+		*out = make([]GrantFrom, len(*in))
+		copy(*out, *in)
+	}
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]GrantTo, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldExportGrantSpec.
+func (in *FieldExportGrantSpec) DeepCopy() *FieldExportGrantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldExportGrantSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrantFrom) DeepCopyInto(out *GrantFrom) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantFrom.
+func (in *GrantFrom) DeepCopy() *GrantFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(GrantFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrantTo) DeepCopyInto(out *GrantTo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrantTo.
+func (in *GrantTo) DeepCopy() *GrantTo {
+	if in == nil {
+		return nil
+	}
+	out := new(GrantTo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Output) DeepCopyInto(out *Output) {
+	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]Field, len(*in))
+		copy(*out, *in)
+	}
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Output.
+func (in *Output) DeepCopy() *Output {
+	if in == nil {
+		return nil
+	}
+	out := new(Output)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutputFallback) DeepCopyInto(out *OutputFallback) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputFallback.
+func (in *OutputFallback) DeepCopy() *OutputFallback {
+	if in == nil {
+		return nil
+	}
+	out := new(OutputFallback)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutputStatus) DeepCopyInto(out *OutputStatus) {
+	*out = *in
+	if in.LastChanged != nil {
+		in, out := &in.LastChanged, &out.LastChanged
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputStatus.
+func (in *OutputStatus) DeepCopy() *OutputStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OutputStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreviewEntry) DeepCopyInto(out *PreviewEntry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreviewEntry.
+func (in *PreviewEntry) DeepCopy() *PreviewEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(PreviewEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequiredFields) DeepCopyInto(out *RequiredFields) {
+	*out = *in
+	if in.StatusConditions != nil {
+		in, out := &in.StatusConditions, &out.StatusConditions
+		*out = make([]StatusCondition, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequiredFields.
+func (in *RequiredFields) DeepCopy() *RequiredFields {
+	if in == nil {
+		return nil
+	}
+	out := new(RequiredFields)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceFieldExport) DeepCopyInto(out *ResourceFieldExport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceFieldExport.
+func (in *ResourceFieldExport) DeepCopy() *ResourceFieldExport {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceFieldExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResourceFieldExport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceFieldExportList) DeepCopyInto(out *ResourceFieldExportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ResourceFieldExport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceFieldExportList.
+func (in *ResourceFieldExportList) DeepCopy() *ResourceFieldExportList {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceFieldExportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResourceFieldExportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceFieldExportSpec) DeepCopyInto(out *ResourceFieldExportSpec) {
+	*out = *in
+	if in.From != nil {
+		in, out := &in.From, &out.From
+		*out = new(ResourceRef)
+		**out = **in
+	}
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]Source, len(*in))
+		copy(*out, *in)
+	}
+	out.To = in.To
+	if in.RequiredFields != nil {
+		in, out := &in.RequiredFields, &out.RequiredFields
+		*out = new(RequiredFields)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = make([]Output, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceFieldExportSpec.
+func (in *ResourceFieldExportSpec) DeepCopy() *ResourceFieldExportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceFieldExportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceFieldExportStatus) DeepCopyInto(out *ResourceFieldExportStatus) {
+	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExportedKeys != nil {
+		in, out := &in.ExportedKeys, &out.ExportedKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExportedTo != nil {
+		in, out := &in.ExportedTo, &out.ExportedTo
+		*out = new(ExportedDestination)
+		**out = **in
+	}
+	if in.Fallbacks != nil {
+		in, out := &in.Fallbacks, &out.Fallbacks
+		*out = make([]OutputFallback, len(*in))
+		copy(*out, *in)
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = make([]OutputStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Preview != nil {
+		in, out := &in.Preview, &out.Preview
+		*out = make([]PreviewEntry, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceFieldExportStatus.
+func (in *ResourceFieldExportStatus) DeepCopy() *ResourceFieldExportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceFieldExportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceRef) DeepCopyInto(out *ResourceRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRef.
+func (in *ResourceRef) DeepCopy() *ResourceRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Source) DeepCopyInto(out *Source) {
+	*out = *in
+	out.ResourceRef = in.ResourceRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Source.
+func (in *Source) DeepCopy() *Source {
+	if in == nil {
+		return nil
+	}
+	out := new(Source)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusCondition) DeepCopyInto(out *StatusCondition) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusCondition.
+func (in *StatusCondition) DeepCopy() *StatusCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusCondition)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,358 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// field-exporter-cli evaluates ResourceFieldExports against source objects read from manifest
+// files, without a cluster, and prints the ConfigMaps and Secrets they would write.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	gdpv1alpha1 "github.com/deliveryhero/field-exporter/api/v1alpha1"
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
+	"github.com/deliveryhero/field-exporter/internal/controller/resourcefieldexport"
+	"github.com/deliveryhero/field-exporter/internal/resourcemanager"
+)
+
+// files is a flag that can be repeated
+type files []string
+
+func (f *files) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *files) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func main() {
+	var manifests, destinations files
+	allowedGroups := resourcemanager.DefaultGroups
+	clusterScope := resourcefieldexport.ClusterScope{
+		Groups:         resourcemanager.CrossplaneGroups,
+		GrantNamespace: "field-exporter-system",
+	}
+	flag.Var(&manifests, "f", "Manifest file holding ResourceFieldExports, their source objects, "+
+		"ExportableResources registering their kinds and FieldExportGrants of other namespaces, can be repeated.")
+	flag.Var(&destinations, "destination",
+		"Manifest file holding the current ConfigMaps and Secrets, can be repeated. "+
+			"When set a diff against the current destinations is printed instead of the destinations.")
+	flag.Var(&allowedGroups, "allowed-groups",
+		"Comma separated glob patterns of the API groups exports can read from, as configured for the manager.")
+	flag.Var(&clusterScope.Groups, "cluster-scoped-groups",
+		"Comma separated glob patterns of the API groups whose source kinds are cluster scoped.")
+	flag.StringVar(&clusterScope.GrantNamespace, "cluster-grant-namespace", clusterScope.GrantNamespace,
+		"Namespace whose FieldExportGrants allow exports to read from cluster scoped sources, as configured for the manager.")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s -f export.yaml -f source.yaml [-destination configmap.yaml]\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if len(manifests) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(os.Stdout, os.Stderr, manifests, destinations, allowedGroups, clusterScope); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run prints the destinations of the exports in the manifests, or their diff against the current
+// destinations, to out. Notices about fallbacks go to errOut.
+func run(out, errOut io.Writer, manifests, destinations []string, groups resourcemanager.Groups, clusterScope resourcefieldexport.ClusterScope) error {
+	objects, err := readObjects(manifests)
+	if err != nil {
+		return err
+	}
+	var current []*unstructured.Unstructured
+	if len(destinations) > 0 {
+		if current, err = readObjects(destinations); err != nil {
+			return err
+		}
+	}
+
+	sources, err := registeredKinds(objects, groups)
+	if err != nil {
+		return err
+	}
+	grants, err := fieldExportGrants(objects)
+	if err != nil {
+		return err
+	}
+
+	// the reconciler logs every broken output, the returned errors already report them
+	ctx := log.IntoContext(context.Background(), logr.Discard())
+	var exports int
+	var errs []error
+	for _, object := range objects {
+		if object.GroupVersionKind().GroupKind() != gdpv1beta1.GroupVersion.WithKind("ResourceFieldExport").GroupKind() {
+			continue
+		}
+		exports++
+		export, err := toHub(object)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", object.GetName(), err))
+			continue
+		}
+		evaluation, err := resourcefieldexport.Evaluate(ctx, export, objects, sources, grants, clusterScope)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", export.Name, err))
+			continue
+		}
+		for _, fallback := range evaluation.Fallbacks {
+			fmt.Fprintf(errOut, "%s: output %s resolved to null, applied %s\n", export.Name, fallback.Key, fallback.Policy)
+		}
+		if len(destinations) > 0 {
+			err = printDiff(out, export, evaluation, current)
+		} else {
+			err = printObject(out, evaluation.Destination)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if exports == 0 {
+		return errors.New("no ResourceFieldExport found in the manifests")
+	}
+	return errors.Join(errs...)
+}
+
+// allowlist allows the kinds registered by the ExportableResources in the manifests besides the
+// allowed groups.
+type allowlist struct {
+	groups resourcemanager.Groups
+	kinds  []gdpv1beta1.ExportableResourceSpec
+}
+
+func (a allowlist) CheckKind(gvk schema.GroupVersionKind) error {
+	for _, kind := range a.kinds {
+		if kind.Group == gvk.Group && kind.Kind == gvk.Kind && (len(kind.Versions) == 0 || slices.Contains(kind.Versions, gvk.Version)) {
+			return nil
+		}
+	}
+	return a.groups.CheckKind(gvk)
+}
+
+func registeredKinds(objects []*unstructured.Unstructured, groups resourcemanager.Groups) (allowlist, error) {
+	sources := allowlist{groups: groups}
+	for _, object := range objects {
+		if object.GroupVersionKind() != gdpv1beta1.GroupVersion.WithKind("ExportableResource") {
+			continue
+		}
+		exportable := &gdpv1beta1.ExportableResource{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, exportable); err != nil {
+			return sources, fmt.Errorf("%s: %w", object.GetName(), err)
+		}
+		sources.kinds = append(sources.kinds, exportable.Spec)
+	}
+	return sources, nil
+}
+
+// fieldExportGrants returns the FieldExportGrants in the manifests, they allow exports to read
+// from and write to other namespaces.
+func fieldExportGrants(objects []*unstructured.Unstructured) ([]gdpv1beta1.FieldExportGrant, error) {
+	var grants []gdpv1beta1.FieldExportGrant
+	for _, object := range objects {
+		if object.GroupVersionKind() != gdpv1beta1.GroupVersion.WithKind("FieldExportGrant") {
+			continue
+		}
+		grant := gdpv1beta1.FieldExportGrant{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &grant); err != nil {
+			return nil, fmt.Errorf("%s: %w", object.GetName(), err)
+		}
+		grants = append(grants, grant)
+	}
+	return grants, nil
+}
+
+// readObjects decodes every document of the YAML or JSON files.
+func readObjects(paths []string) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		decoder := utilyaml.NewYAMLOrJSONDecoder(f, 4096)
+		for {
+			object := &unstructured.Unstructured{}
+			err := decoder.Decode(&object.Object)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				f.Close()
+				return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+			}
+			if len(object.Object) > 0 {
+				objects = append(objects, object)
+			}
+		}
+		f.Close()
+	}
+	return objects, nil
+}
+
+// toHub converts an export of any served version to the version the controller reconciles.
+func toHub(object *unstructured.Unstructured) (*gdpv1beta1.ResourceFieldExport, error) {
+	export := &gdpv1beta1.ResourceFieldExport{}
+	switch object.GetAPIVersion() {
+	case gdpv1beta1.GroupVersion.String():
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, export); err != nil {
+			return nil, err
+		}
+	case gdpv1alpha1.GroupVersion.String():
+		spoke := &gdpv1alpha1.ResourceFieldExport{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, spoke); err != nil {
+			return nil, err
+		}
+		if err := spoke.ConvertTo(export); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported apiVersion %s", object.GetAPIVersion())
+	}
+	return export, nil
+}
+
+func printObject(out io.Writer, object client.Object) error {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return err
+	}
+	unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "---\n%s", data)
+	return err
+}
+
+// printDiff prints the keys the export would add, change or remove in its current destination.
+// Removed keys are the stale keys of status.exportedKeys, keys written by others are left alone
+// by the export and not shown. Values of Secrets are never printed, only whether a key is added,
+// changed or removed.
+func printDiff(out io.Writer, export *gdpv1beta1.ResourceFieldExport, evaluation *resourcefieldexport.Evaluation, current []*unstructured.Unstructured) error {
+	destination := evaluation.Destination
+	values, err := destinationData(destination)
+	if err != nil {
+		return err
+	}
+	var existing map[string]string
+	for _, object := range current {
+		if object.GetKind() == string(export.Spec.To.Type) && object.GetName() == export.Spec.To.Name &&
+			(object.GetNamespace() == "" || destination.GetNamespace() == "" || object.GetNamespace() == destination.GetNamespace()) {
+			if existing, err = destinationData(object); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	fmt.Fprintf(out, "%s %s (%s)\n", export.Spec.To.Type, export.Spec.To.Name, export.Name)
+	if existing == nil {
+		fmt.Fprintln(out, "  destination not found, it would be created")
+	}
+	keys := make([]string, 0, len(values)+len(evaluation.Stale))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	keys = append(keys, evaluation.Stale...)
+	sort.Strings(keys)
+	secret := export.Spec.To.Type == gdpv1beta1.Secret
+	var changed bool
+	for _, k := range keys {
+		old, ok := existing[k]
+		value, exported := values[k]
+		switch {
+		case !exported && ok && secret:
+			fmt.Fprintf(out, "- %s (removed)\n", k)
+		case !exported && ok:
+			fmt.Fprintf(out, "- %s: %s\n", k, old)
+		case !exported:
+			continue
+		case !ok && secret:
+			fmt.Fprintf(out, "+ %s (added)\n", k)
+		case !ok:
+			fmt.Fprintf(out, "+ %s: %s\n", k, value)
+		case old != value && secret:
+			fmt.Fprintf(out, "~ %s (changed)\n", k)
+		case old != value:
+			fmt.Fprintf(out, "- %s: %s\n+ %s: %s\n", k, old, k, value)
+		default:
+			continue
+		}
+		changed = true
+	}
+	if !changed {
+		fmt.Fprintln(out, "  no changes")
+	}
+	return nil
+}
+
+// destinationData returns the decoded data of a ConfigMap or Secret.
+func destinationData(object client.Object) (map[string]string, error) {
+	if u, ok := object.(*unstructured.Unstructured); ok {
+		switch u.GetKind() {
+		case "Secret":
+			object = &corev1.Secret{}
+		case "ConfigMap":
+			object = &corev1.ConfigMap{}
+		default:
+			return nil, fmt.Errorf("unsupported destination kind %s", u.GetKind())
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, object); err != nil {
+			return nil, err
+		}
+	}
+	data := make(map[string]string)
+	switch o := object.(type) {
+	case *corev1.ConfigMap:
+		for k, v := range o.Data {
+			data[k] = v
+		}
+	case *corev1.Secret:
+		for k, v := range o.Data {
+			data[k] = string(v)
+		}
+		for k, v := range o.StringData {
+			data[k] = v
+		}
+	}
+	return data, nil
+}
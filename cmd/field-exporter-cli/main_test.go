@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/deliveryhero/field-exporter/internal/controller/resourcefieldexport"
+	"github.com/deliveryhero/field-exporter/internal/resourcemanager"
+)
+
+var clusterScope = resourcefieldexport.ClusterScope{Groups: resourcemanager.CrossplaneGroups, GrantNamespace: "field-exporter-system"}
+
+const redisInstance = `
+apiVersion: redis.cnrm.cloud.google.com/v1beta1
+kind: RedisInstance
+metadata:
+  name: myapp-redis
+  namespace: default
+status:
+  host: 10.0.0.3
+  port: 6379
+`
+
+const export = `
+apiVersion: gdp.deliveryhero.io/v1beta1
+kind: ResourceFieldExport
+metadata:
+  name: myapp-redis
+  namespace: default
+spec:
+  from:
+    apiVersion: redis.cnrm.cloud.google.com/v1beta1
+    kind: RedisInstance
+    name: myapp-redis
+  to:
+    type: ConfigMap
+    name: myapp-redis-config
+  outputs:
+  - key: endpoint
+    path: .status.host
+  - key: port
+    path: .status.port
+  - key: read-endpoint
+    path: .status.readEndpoint
+    optional: true
+`
+
+func TestRun(t *testing.T) {
+	var out, errOut bytes.Buffer
+	err := run(&out, &errOut, []string{manifest(t, export), manifest(t, redisInstance)}, nil, resourcemanager.DefaultGroups, clusterScope)
+	require.NoError(t, err)
+	require.Equal(t, `---
+apiVersion: v1
+data:
+  endpoint: 10.0.0.3
+  port: "6379"
+kind: ConfigMap
+metadata:
+  name: myapp-redis-config
+  namespace: default
+`, out.String())
+	require.Equal(t, "myapp-redis: output read-endpoint resolved to null, applied Skip\n", errOut.String())
+}
+
+func TestRunDiff(t *testing.T) {
+	// the export wrote the host key before the output was renamed to endpoint
+	exported := export + `
+status:
+  exportedKeys:
+  - host
+  - port
+`
+	destination := manifest(t, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: myapp-redis-config
+  namespace: default
+data:
+  host: 10.0.0.2
+  port: "6379"
+  owner: platform
+`)
+	var out, errOut bytes.Buffer
+	err := run(&out, &errOut, []string{manifest(t, exported), manifest(t, redisInstance)}, []string{destination}, resourcemanager.DefaultGroups, clusterScope)
+	require.NoError(t, err)
+	require.Equal(t, `ConfigMap myapp-redis-config (myapp-redis)
++ endpoint: 10.0.0.3
+- host: 10.0.0.2
+`, out.String())
+
+	out.Reset()
+	err = run(&out, &errOut, []string{manifest(t, export), manifest(t, redisInstance)}, []string{manifest(t, "")}, resourcemanager.DefaultGroups, clusterScope)
+	require.NoError(t, err)
+	require.Equal(t, `ConfigMap myapp-redis-config (myapp-redis)
+  destination not found, it would be created
++ endpoint: 10.0.0.3
++ port: 6379
+`, out.String())
+}
+
+func TestRunDiffSecret(t *testing.T) {
+	exported := strings.Replace(export, "type: ConfigMap", "type: Secret", 1) + `
+status:
+  exportedKeys:
+  - host
+  - port
+  - endpoint
+`
+	destination := manifest(t, `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: myapp-redis-config
+  namespace: default
+stringData:
+  host: 10.0.0.2
+  port: "6379"
+  endpoint: 10.0.0.2
+`)
+	var out, errOut bytes.Buffer
+	err := run(&out, &errOut, []string{manifest(t, exported), manifest(t, redisInstance)}, []string{destination}, resourcemanager.DefaultGroups, clusterScope)
+	require.NoError(t, err)
+	require.Equal(t, `Secret myapp-redis-config (myapp-redis)
+~ endpoint (changed)
+- host (removed)
+`, out.String())
+	require.NotContains(t, out.String(), "10.0.0.")
+}
+
+func TestRunErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		manifests []string
+		expectErr string
+	}{
+		{
+			name:      "no exports",
+			manifests: []string{redisInstance},
+			expectErr: "no ResourceFieldExport found in the manifests",
+		},
+		{
+			name:      "missing source",
+			manifests: []string{export},
+			expectErr: "myapp-redis: source RedisInstance myapp-redis not found",
+		},
+		{
+			name: "source in another namespace without a grant",
+			manifests: []string{
+				strings.Replace(export, "    name: myapp-redis\n", "    name: myapp-redis\n    namespace: platform\n", 1),
+				strings.Replace(redisInstance, "namespace: default", "namespace: platform", 1),
+			},
+			expectErr: "myapp-redis: reference not permitted: no FieldExportGrant in namespace platform allows exports of namespace default to refer to RedisInstance.redis.cnrm.cloud.google.com myapp-redis",
+		},
+		{
+			name: "cluster scoped source without a grant",
+			manifests: []string{
+				strings.NewReplacer(
+					"redis.cnrm.cloud.google.com/v1beta1", "s3.aws.upbound.io/v1beta1",
+					"RedisInstance", "Bucket",
+				).Replace(export),
+				`
+apiVersion: s3.aws.upbound.io/v1beta1
+kind: Bucket
+metadata:
+  name: myapp-redis
+`,
+			},
+			expectErr: "myapp-redis: reference not permitted: no FieldExportGrant in namespace field-exporter-system allows exports of namespace default to refer to Bucket.s3.aws.upbound.io myapp-redis",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			paths := make([]string, 0, len(tc.manifests))
+			for _, m := range tc.manifests {
+				paths = append(paths, manifest(t, m))
+			}
+			var out, errOut bytes.Buffer
+			err := run(&out, &errOut, paths, nil, resourcemanager.DefaultGroups, clusterScope)
+			require.EqualError(t, err, tc.expectErr)
+		})
+	}
+}
+
+// manifest writes the content to a file and returns its path.
+func manifest(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "manifest.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
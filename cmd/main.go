@@ -17,24 +17,36 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"os"
+	"slices"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/yaml"
 
 	gdpv1alpha1 "github.com/deliveryhero/field-exporter/api/v1alpha1"
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
+	"github.com/deliveryhero/field-exporter/internal/controller/exportableresource"
 	"github.com/deliveryhero/field-exporter/internal/controller/resourcefieldexport"
+	"github.com/deliveryhero/field-exporter/internal/namespaces"
 	"github.com/deliveryhero/field-exporter/internal/resourcemanager"
 	//+kubebuilder:scaffold:imports
 )
@@ -44,10 +56,15 @@ var (
 	setupLog = ctrl.Log.WithName("setup")
 )
 
+// deployment names the objects config/default deploys, the RBAC printed with --print-rbac
+// refers to them
+var deployment = namespaces.Deployment{Namespace: "field-exporter-system", NamePrefix: "field-exporter-"}
+
 func init() {
 	// add schemes of config-connector resources
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(gdpv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(gdpv1beta1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -55,11 +72,37 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var strictPathValidation bool
+	var discoveryInterval time.Duration
+	var watchNamespaces namespaces.Namespaces
+	var printRBAC bool
+	var clusterGrantNamespace string
+	allowedGroups := resourcemanager.DefaultGroups
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&strictPathValidation, "strict-path-validation", false,
+		"Reject exports whose output paths refer to fields the schema of the source doesn't define, "+
+			"by default they are admitted with a warning.")
+	flag.Var(&allowedGroups, "allowed-groups",
+		"Comma separated glob patterns of the API groups exports can read from, e.g. *.cnrm.cloud.google.com. "+
+			"The manager must be allowed to get, list and watch the resources of these groups.")
+	flag.DurationVar(&discoveryInterval, "discovery-interval", time.Minute,
+		"How often the allowed groups are discovered again, so kinds installed or removed after the start "+
+			"are picked up. 0 discovers them only at startup.")
+	flag.Var(&watchNamespaces, "watch-namespaces",
+		"Comma separated namespaces, or a label selector of namespaces, e.g. team in (payments,checkout), "+
+			"the manager is restricted to. Namespaces are matched against the selector at startup. "+
+			"The manager watches the whole cluster when empty.")
+	flag.StringVar(&clusterGrantNamespace, "cluster-grant-namespace", deployment.Namespace,
+		"Namespace whose FieldExportGrants allow exports to read from cluster scoped sources, e.g. Crossplane "+
+			"managed resources. Only administrators should be able to write to it. Cluster scoped sources are "+
+			"denied when empty.")
+	flag.BoolVar(&printRBAC, "print-rbac", false,
+		"Print the RoleBindings and the ClusterRole of a manager restricted to --watch-namespaces, "+
+			"which replace the ClusterRoleBindings of config/default, and exit.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -67,9 +110,30 @@ func main() {
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	watchedNamespaces, err := resolveNamespaces(watchNamespaces)
+	if err != nil {
+		setupLog.Error(err, "unable to resolve namespaces to watch", "watchNamespaces", watchNamespaces.String())
+		os.Exit(1)
+	}
+	// the grants of the cluster grant namespace are read by a manager restricted to namespaces too
+	if len(watchedNamespaces) > 0 && clusterGrantNamespace != "" && !slices.Contains(watchedNamespaces, clusterGrantNamespace) {
+		watchedNamespaces = append(watchedNamespaces, clusterGrantNamespace)
+	}
+	if printRBAC {
+		if err := printNamespacedRBAC(watchedNamespaces); err != nil {
+			setupLog.Error(err, "unable to print RBAC")
+			os.Exit(1)
+		}
+		return
+	}
+	if len(watchedNamespaces) > 0 {
+		setupLog.Info("restricted to namespaces", "namespaces", watchedNamespaces)
+	}
+
 	restConfig := ctrl.GetConfigOrDie()
 	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme:                 scheme,
+		Cache:                  cache.Options{DefaultNamespaces: namespaces.DefaultNamespaces(watchedNamespaces)},
 		Metrics:                metricsserver.Options{BindAddress: metricsAddr},
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
@@ -97,23 +161,53 @@ func main() {
 		os.Exit(1)
 	}
 
-	resourceManager, err := resourcemanager.NewResourceManager(discoveryClient)
+	resourceManager, err := resourcemanager.NewResourceManager(discoveryClient, allowedGroups)
 	if err != nil {
 		setupLog.Error(err, "unable to create resource manager")
 		os.Exit(1)
 	}
-	setupLog.Info("resource manager initialized", "discoveredResources", len(resourceManager.Resources()))
+	setupLog.Info("resource manager initialized", "discoveredResources", len(resourceManager.Resources()), "allowedGroups", allowedGroups)
 
-	if err = (&resourcefieldexport.Reconciler{
+	exportReconciler := &resourcefieldexport.Reconciler{
 		Client:  mgr.GetClient(),
 		Scheme:  mgr.GetScheme(),
 		Manager: resourceManager,
-	}).SetupWithManager(mgr); err != nil {
+
+		ClusterGrantNamespace: clusterGrantNamespace,
+	}
+	if err = exportReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ResourceFieldExport")
 		os.Exit(1)
 	}
+	if discoveryInterval > 0 {
+		if err = mgr.Add(&resourcemanager.Rediscovery{
+			Manager:  resourceManager,
+			Watches:  exportReconciler,
+			Interval: discoveryInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up rediscovery")
+			os.Exit(1)
+		}
+	}
+	// kinds registered with an ExportableResource are watched by the ResourceFieldExport controller
+	if err = (&exportableresource.Reconciler{
+		Client:     mgr.GetClient(),
+		Manager:    resourceManager,
+		Watches:    exportReconciler,
+		Namespaces: watchedNamespaces,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ExportableResource")
+		os.Exit(1)
+	}
 	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
-		if err = (&gdpv1alpha1.ResourceFieldExport{}).SetupWebhookWithManager(mgr, resourceManager); err != nil {
+		apiextensionsClient, err := apiextensionsclientset.NewForConfig(restConfig)
+		if err != nil {
+			setupLog.Error(err, "unable to create apiextensions client")
+			os.Exit(1)
+		}
+		resourceManager.WithSchemas(apiextensionsClient.ApiextensionsV1().CustomResourceDefinitions())
+		// registers the validating webhook and the conversion webhook between all versions
+		if err = (&gdpv1beta1.ResourceFieldExport{}).SetupWebhookWithManager(mgr, resourceManager, strictPathValidation); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "ResourceFieldExport")
 			os.Exit(1)
 		}
@@ -135,3 +229,39 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// resolveNamespaces returns the namespaces of --watch-namespaces, a selector is matched against
+// the namespaces of the cluster.
+func resolveNamespaces(watch namespaces.Namespaces) ([]string, error) {
+	if watch.Selector == nil {
+		return watch.Names, nil
+	}
+	restConfig, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	c, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+	return watch.Resolve(context.Background(), c)
+}
+
+func printNamespacedRBAC(watchedNamespaces []string) error {
+	if len(watchedNamespaces) == 0 {
+		return fmt.Errorf("--print-rbac requires --watch-namespaces")
+	}
+	for _, object := range namespaces.RBAC(deployment, watchedNamespaces) {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+		if err != nil {
+			return err
+		}
+		unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+		data, err := yaml.Marshal(content)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("---\n%s", data)
+	}
+	return nil
+}
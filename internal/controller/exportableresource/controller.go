@@ -0,0 +1,122 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exportableresource
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
+	"github.com/deliveryhero/field-exporter/internal/resourcemanager"
+)
+
+// retryInterval is how often registrations of kinds that aren't served or readable are retried,
+// neither installing a CRD nor granting a role triggers a reconcile
+const retryInterval = time.Minute
+
+// Reconciler reconciles an ExportableResource object
+type Reconciler struct {
+	client.Client
+	Manager *resourcemanager.ResourceManager
+	Watches resourcemanager.Watches
+	// Namespaces are the namespaces the manager is restricted to, all when empty
+	Namespaces []string
+}
+
+//+kubebuilder:rbac:groups=gdp.deliveryhero.io,resources=exportableresources,verbs=get;list;watch
+//+kubebuilder:rbac:groups=gdp.deliveryhero.io,resources=exportableresources/status,verbs=get;update;patch
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	exportable := &gdpv1beta1.ExportableResource{}
+	if err := r.Get(ctx, req.NamespacedName, exportable); err != nil {
+		if apierrors.IsNotFound(err) {
+			// the kinds are unregistered without a finalizer, registrations are keyed by name
+			return ctrl.Result{}, r.unregister(ctx, req.Name)
+		}
+		logger.Error(err, "failed to get ExportableResource")
+		return ctrl.Result{}, err
+	}
+
+	gk := schema.GroupKind{Group: exportable.Spec.Group, Kind: exportable.Spec.Kind}
+	mappings, err := r.RESTMapper().RESTMappings(gk, exportable.Spec.Versions...)
+	if err != nil {
+		if !meta.IsNoMatchError(err) {
+			logger.Error(err, "failed to map kind", "groupKind", gk)
+			return ctrl.Result{}, err
+		}
+		if err := r.unregister(ctx, exportable.Name); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("kind is not served, will retry", "groupKind", gk)
+		return r.notReadyStatus(ctx, exportable, nil, gdpv1beta1.ReasonKindNotFound, fmt.Sprintf("kind %s is not served by the cluster", gk))
+	}
+
+	resources := make(map[schema.GroupVersionKind]string, len(mappings))
+	namespaced := false
+	for _, mapping := range mappings {
+		resources[mapping.GroupVersionKind] = mapping.Resource.Resource
+		namespaced = namespaced || mapping.Scope.Name() == meta.RESTScopeNameNamespace
+	}
+	rules := policyRules(gk.Group, resources)
+
+	denied, err := r.deniedVerbs(ctx, gk.Group, rules, namespaced)
+	if err != nil {
+		logger.Error(err, "failed to review access", "groupKind", gk)
+		return ctrl.Result{}, err
+	}
+	if len(denied) > 0 {
+		if err := r.unregister(ctx, exportable.Name); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("manager can't read kind, will retry", "groupKind", gk, "verbs", denied)
+		return r.notReadyStatus(ctx, exportable, rules, gdpv1beta1.ReasonForbidden,
+			fmt.Sprintf("the manager is not allowed to %v %s, grant the rules in the status with a ClusterRole labelled %s: \"true\"",
+				denied, gk, sourceReaderLabel))
+	}
+
+	added, removed := r.Manager.Register(exportable.Name, resources)
+	if err := resourcemanager.UpdateWatches(ctx, r.Watches, added, removed); err != nil {
+		logger.Error(err, "failed to update watches", "groupKind", gk)
+		return ctrl.Result{}, err
+	}
+	return r.readyStatus(ctx, exportable, resources, rules)
+}
+
+// SetupWithManager sets up the controller with the Manager. It runs on every replica without
+// leader election, the webhook of every replica validates against the registered kinds.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gdpv1beta1.ExportableResource{}).
+		WithOptions(controller.Options{NeedLeaderElection: ptr.To(false)}).
+		Complete(r)
+}
+
+func (r *Reconciler) unregister(ctx context.Context, name string) error {
+	return resourcemanager.UpdateWatches(ctx, r.Watches, nil, r.Manager.Unregister(name))
+}
@@ -0,0 +1,173 @@
+package exportableresource
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive
+	. "github.com/onsi/gomega"    //nolint:revive
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/rand"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
+)
+
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+var _ = Describe("ExportableResource controller", func() {
+	var testNamespace string
+
+	BeforeEach(func() {
+		testNamespace = fmt.Sprintf("test-%03d", rand.Intn(10000))
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testNamespace}}
+		Expect(k8sClient.Create(context.Background(), namespace)).Should(Succeed())
+	})
+
+	Context("for a served kind", func() {
+		var exportable *gdpv1beta1.ExportableResource
+
+		BeforeEach(func() {
+			exportable = &gdpv1beta1.ExportableResource{
+				ObjectMeta: metav1.ObjectMeta{Name: "certificates"},
+				Spec: gdpv1beta1.ExportableResourceSpec{
+					Group: certificateGVK.Group,
+					Kind:  certificateGVK.Kind,
+				},
+			}
+			Expect(k8sClient.Create(context.Background(), exportable)).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(cr.IgnoreNotFound(k8sClient.Delete(context.Background(), exportable))).Should(Succeed())
+			Eventually(func() error {
+				return resourceManager.CheckKind(certificateGVK)
+			}, "10s").Should(HaveOccurred())
+		})
+
+		It("should register the kind and report the rules the manager needs", func() {
+			ctx := context.Background()
+			Eventually(func() gdpv1beta1.ExportableResourceStatus {
+				_ = k8sClient.Get(ctx, cr.ObjectKeyFromObject(exportable), exportable)
+				return exportable.Status
+			}, "10s").Should(And(
+				HaveField("Resource", "certificates"),
+				HaveField("Versions", []string{"v1"}),
+				HaveField("Rules", []rbacv1.PolicyRule{{
+					APIGroups: []string{"cert-manager.io"},
+					Resources: []string{"certificates"},
+					Verbs:     []string{"get", "list", "watch"},
+				}}),
+				HaveField("Conditions", ConsistOf(And(
+					HaveField("Type", gdpv1beta1.ReadyCondition),
+					HaveField("Status", metav1.ConditionTrue),
+					HaveField("Reason", gdpv1beta1.ReasonRegistered),
+				))),
+			))
+			Expect(resourceManager.Validate("cert-manager.io/v1", "Certificate")).Should(Succeed())
+		})
+
+		It("should export fields of the registered kind and follow its changes", func() {
+			ctx := context.Background()
+			Eventually(func() error {
+				return resourceManager.CheckKind(certificateGVK)
+			}, "10s").Should(Succeed())
+
+			certificate := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "cert-manager.io/v1",
+					"kind":       "Certificate",
+					"metadata": map[string]interface{}{
+						"name":      "tls",
+						"namespace": testNamespace,
+					},
+					"spec": map[string]interface{}{
+						"secretName": "tls",
+						"dnsNames":   []interface{}{"app.example.com"},
+						"issuerRef": map[string]interface{}{
+							"name": "letsencrypt",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, certificate)).Should(Succeed())
+			certificate.Object["status"] = map[string]interface{}{
+				"notAfter": "2025-01-01T00:00:00Z",
+			}
+			Expect(k8sClient.Status().Update(ctx, certificate)).Should(Succeed())
+
+			rfe := &gdpv1beta1.ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "tls-expiry",
+					Namespace: testNamespace,
+				},
+				Spec: gdpv1beta1.ResourceFieldExportSpec{
+					From: &gdpv1beta1.ResourceRef{
+						APIVersion: "cert-manager.io/v1",
+						Kind:       "Certificate",
+						Name:       "tls",
+					},
+					To: gdpv1beta1.DestinationRef{
+						Type:         gdpv1beta1.ConfigMap,
+						Name:         "tls-expiry",
+						CreatePolicy: gdpv1beta1.CreateIfMissing,
+					},
+					Outputs: []gdpv1beta1.Output{
+						{
+							Key:  "not-after",
+							Path: ".status.notAfter",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+
+			notAfter := func() string {
+				cm := &corev1.ConfigMap{}
+				_ = k8sClient.Get(ctx, cr.ObjectKey{Namespace: testNamespace, Name: "tls-expiry"}, cm)
+				return cm.Data["not-after"]
+			}
+			Eventually(notAfter, "10s").Should(Equal("2025-01-01T00:00:00Z"))
+
+			// the renewal is picked up through the watch started for the registered kind
+			certificate.Object["status"] = map[string]interface{}{
+				"notAfter": "2025-04-01T00:00:00Z",
+			}
+			Expect(k8sClient.Status().Update(ctx, certificate)).Should(Succeed())
+			Eventually(notAfter, "10s").Should(Equal("2025-04-01T00:00:00Z"))
+		})
+	})
+
+	Context("for a kind the cluster doesn't serve", func() {
+		It("should report the kind as not found", func() {
+			ctx := context.Background()
+			exportable := &gdpv1beta1.ExportableResource{
+				ObjectMeta: metav1.ObjectMeta{Name: "kafkausers"},
+				Spec: gdpv1beta1.ExportableResourceSpec{
+					Group: "kafka.strimzi.io",
+					Kind:  "KafkaUser",
+				},
+			}
+			Expect(k8sClient.Create(ctx, exportable)).Should(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(context.Background(), exportable)).Should(Succeed())
+			})
+
+			Eventually(func() []metav1.Condition {
+				_ = k8sClient.Get(ctx, cr.ObjectKeyFromObject(exportable), exportable)
+				return exportable.Status.Conditions
+			}, "10s").Should(ConsistOf(And(
+				HaveField("Type", gdpv1beta1.ReadyCondition),
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", gdpv1beta1.ReasonKindNotFound),
+				HaveField("Message", "kind KafkaUser.kafka.strimzi.io is not served by the cluster"),
+			)))
+			Expect(resourceManager.Validate("kafka.strimzi.io/v1beta2", "KafkaUser")).ShouldNot(Succeed())
+		})
+	})
+})
@@ -0,0 +1,76 @@
+package exportableresource
+
+import (
+	"context"
+	"slices"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// sourceReaderLabel aggregates ClusterRoles into the source-reader-role of the manager
+const sourceReaderLabel = "gdp.deliveryhero.io/aggregate-to-source-reader"
+
+// sourceVerbs are the verbs the manager needs on a source kind
+var sourceVerbs = []string{"get", "list", "watch"}
+
+// deniedVerbs reviews whether the manager is granted the rules in every namespace it reads from,
+// it returns the verbs it isn't.
+func (r *Reconciler) deniedVerbs(ctx context.Context, group string, rules []rbacv1.PolicyRule, namespaced bool) ([]string, error) {
+	var denied []string
+	for _, rule := range rules {
+		for _, resource := range rule.Resources {
+			for _, verb := range rule.Verbs {
+				for _, namespace := range r.reviewNamespaces(namespaced) {
+					review := &authorizationv1.SelfSubjectAccessReview{
+						Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+							ResourceAttributes: &authorizationv1.ResourceAttributes{
+								Namespace: namespace,
+								Group:     group,
+								Resource:  resource,
+								Verb:      verb,
+							},
+						},
+					}
+					if err := r.Create(ctx, review); err != nil {
+						return nil, err
+					}
+					if !review.Status.Allowed && !slices.Contains(denied, verb) {
+						denied = append(denied, verb)
+					}
+				}
+			}
+		}
+	}
+	return denied, nil
+}
+
+// reviewNamespaces returns the namespaces access to a kind is reviewed in, a namespaced kind is
+// only read from the watched namespaces when the manager is restricted to them.
+func (r *Reconciler) reviewNamespaces(namespaced bool) []string {
+	if !namespaced || len(r.Namespaces) == 0 {
+		return []string{metav1.NamespaceAll}
+	}
+	return r.Namespaces
+}
+
+// policyRules returns the rules the manager needs to read the resources.
+func policyRules(group string, resources map[schema.GroupVersionKind]string) []rbacv1.PolicyRule {
+	var plurals []string
+	for _, plural := range resources {
+		if !slices.Contains(plurals, plural) {
+			plurals = append(plurals, plural)
+		}
+	}
+	if len(plurals) == 0 {
+		return nil
+	}
+	slices.Sort(plurals)
+	return []rbacv1.PolicyRule{{
+		APIGroups: []string{group},
+		Resources: plurals,
+		Verbs:     sourceVerbs,
+	}}
+}
@@ -0,0 +1,62 @@
+package exportableresource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestPolicyRules(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		group     string
+		resources map[schema.GroupVersionKind]string
+		expected  []rbacv1.PolicyRule
+	}{
+		{
+			name: "no resources",
+		},
+		{
+			name:  "one resource in every version",
+			group: "cert-manager.io",
+			resources: map[schema.GroupVersionKind]string{
+				{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}:      "certificates",
+				{Group: "cert-manager.io", Version: "v1beta1", Kind: "Certificate"}: "certificates",
+			},
+			expected: []rbacv1.PolicyRule{{
+				APIGroups: []string{"cert-manager.io"},
+				Resources: []string{"certificates"},
+				Verbs:     []string{"get", "list", "watch"},
+			}},
+		},
+		{
+			name:  "resources renamed between versions",
+			group: "kafka.strimzi.io",
+			resources: map[schema.GroupVersionKind]string{
+				{Group: "kafka.strimzi.io", Version: "v1beta2", Kind: "KafkaUser"}:  "kafkausers",
+				{Group: "kafka.strimzi.io", Version: "v1alpha1", Kind: "KafkaUser"}: "kafkauser",
+			},
+			expected: []rbacv1.PolicyRule{{
+				APIGroups: []string{"kafka.strimzi.io"},
+				Resources: []string{"kafkauser", "kafkausers"},
+				Verbs:     []string{"get", "list", "watch"},
+			}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, policyRules(tc.group, tc.resources))
+		})
+	}
+}
+
+func TestReviewNamespaces(t *testing.T) {
+	r := &Reconciler{}
+	require.Equal(t, []string{""}, r.reviewNamespaces(true))
+
+	r.Namespaces = []string{"team-a", "team-b"}
+	require.Equal(t, []string{"team-a", "team-b"}, r.reviewNamespaces(true))
+	// cluster scoped kinds are read cluster-wide either way
+	require.Equal(t, []string{""}, r.reviewNamespaces(false))
+}
@@ -0,0 +1,49 @@
+package exportableresource
+
+import (
+	"context"
+	"slices"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
+)
+
+// notReadyStatus reports why the kind isn't registered, the registration is retried.
+func (r *Reconciler) notReadyStatus(ctx context.Context, exportable *gdpv1beta1.ExportableResource, rules []rbacv1.PolicyRule, reason, message string) (ctrl.Result, error) {
+	status := gdpv1beta1.ExportableResourceStatus{Rules: rules}
+	return ctrl.Result{RequeueAfter: retryInterval}, r.updateStatus(ctx, exportable, status, metav1.ConditionFalse, reason, message)
+}
+
+func (r *Reconciler) readyStatus(ctx context.Context, exportable *gdpv1beta1.ExportableResource, resources map[schema.GroupVersionKind]string, rules []rbacv1.PolicyRule) (ctrl.Result, error) {
+	status := gdpv1beta1.ExportableResourceStatus{Rules: rules}
+	for gvk, plural := range resources {
+		status.Resource = plural
+		status.Versions = append(status.Versions, gvk.Version)
+	}
+	slices.Sort(status.Versions)
+	return ctrl.Result{}, r.updateStatus(ctx, exportable, status, metav1.ConditionTrue, gdpv1beta1.ReasonRegistered, "Exports can read from the kind")
+}
+
+func (r *Reconciler) updateStatus(ctx context.Context, exportable *gdpv1beta1.ExportableResource, status gdpv1beta1.ExportableResourceStatus, conditionStatus metav1.ConditionStatus, reason, message string) error {
+	exportable = exportable.DeepCopy()
+	status.ObservedGeneration = exportable.Generation
+	status.Conditions = exportable.Status.Conditions
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               gdpv1beta1.ReadyCondition,
+		Status:             conditionStatus,
+		ObservedGeneration: exportable.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	if equality.Semantic.DeepEqual(exportable.Status, status) {
+		return nil
+	}
+	exportable.Status = status
+	return r.Status().Update(ctx, exportable)
+}
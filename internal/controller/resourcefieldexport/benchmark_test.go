@@ -0,0 +1,151 @@
+package resourcefieldexport
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive
+	. "github.com/onsi/gomega"    //nolint:revive
+	"github.com/onsi/gomega/gmeasure"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	"github.com/deliveryhero/field-exporter/internal/namespaces"
+)
+
+// sourceObjects is the number of objects of each seeded kind no export reads from
+const sourceObjects = 50
+
+var _ = Describe("Source kind watches", Label("benchmark"), Serial, func() {
+	var benchmarkNamespace string
+
+	BeforeEach(func() {
+		benchmarkNamespace = fmt.Sprintf("benchmark-%03d", rand.Intn(10000))
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: benchmarkNamespace}}
+		Expect(k8sClient.Create(ctx, namespace)).Should(Succeed())
+
+		for i := 0; i < sourceObjects; i++ {
+			Expect(k8sClient.Create(ctx, &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "dbforpostgresql.azure.com/v1api20221201",
+					"kind":       "FlexibleServer",
+					"metadata": map[string]interface{}{
+						"name":      fmt.Sprintf("postgres-%03d", i),
+						"namespace": benchmarkNamespace,
+					},
+					"spec": map[string]interface{}{
+						"location": "westeurope",
+						"owner":    map[string]interface{}{"name": "resource-group"},
+						"version":  "14",
+					},
+				},
+			})).Should(Succeed())
+			Expect(k8sClient.Create(ctx, &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "s3.aws.upbound.io/v1beta1",
+					"kind":       "Bucket",
+					"metadata": map[string]interface{}{
+						"name": fmt.Sprintf("%s-bucket-%03d", benchmarkNamespace, i),
+					},
+					"spec": map[string]interface{}{
+						"forProvider": map[string]interface{}{"region": "eu-west-1"},
+					},
+				},
+			})).Should(Succeed())
+		}
+	})
+
+	It("should list and watch only the kinds exports read from", func() {
+		experiment := gmeasure.NewExperiment("source kind watches")
+		AddReportEntry(experiment.Name, experiment)
+
+		redisGVK := schema.GroupVersionKind{Group: "redis.cnrm.cloud.google.com", Version: "v1beta1", Kind: "RedisInstance"}
+
+		// watching every supported kind is what the controller did before kinds were watched lazily
+		eager := measureWatches(benchmarkNamespace, resourceValidator.Resources())
+		lazy := measureWatches(benchmarkNamespace, []schema.GroupVersionKind{redisGVK})
+		for _, m := range []struct {
+			annotation string
+			watches    watchesMeasurement
+		}{{"eager", eager}, {"lazy", lazy}} {
+			experiment.RecordValue("list requests", float64(m.watches.lists), gmeasure.Annotation(m.annotation))
+			experiment.RecordValue("watch requests", float64(m.watches.watches), gmeasure.Annotation(m.annotation))
+			experiment.RecordValue("heap", float64(m.watches.heap)/1024, gmeasure.Annotation(m.annotation), gmeasure.Units("KiB"))
+		}
+
+		Expect(lazy.lists).To(BeNumerically("<", eager.lists))
+		Expect(lazy.watches).To(BeNumerically("<", eager.watches))
+	})
+})
+
+type watchesMeasurement struct {
+	lists   int
+	watches int
+	heap    int64
+}
+
+// measureWatches runs a manager whose export reads from the given kinds and counts the list and
+// watch requests it sends until its caches are synced
+func measureWatches(namespace string, gvks []schema.GroupVersionKind) watchesMeasurement {
+	recorded, requests := recordRequests(cfg)
+	// the cache is limited to the namespace so exports of the other specs aren't reconciled twice
+	mgr, err := ctrl.NewManager(recorded, ctrl.Options{
+		Scheme:     scheme.Scheme,
+		Metrics:    metricsserver.Options{BindAddress: "0"},
+		Cache:      cache.Options{DefaultNamespaces: namespaces.DefaultNamespaces([]string{namespace})},
+		Controller: config.Controller{SkipNameValidation: ptr.To(true)},
+	})
+	Expect(err).ToNot(HaveOccurred())
+	reconciler := &Reconciler{
+		Client:  mgr.GetClient(),
+		Scheme:  mgr.GetScheme(),
+		Manager: resourceValidator,
+	}
+	Expect(reconciler.SetupWithManager(mgr)).To(Succeed())
+
+	mgrCtx, mgrCancel := context.WithCancel(ctx)
+	defer mgrCancel()
+	before := heapAlloc()
+	go func() {
+		defer GinkgoRecover()
+		Expect(mgr.Start(mgrCtx)).To(Succeed())
+	}()
+	Expect(mgr.GetCache().WaitForCacheSync(mgrCtx)).To(BeTrue())
+
+	export := types.NamespacedName{Namespace: namespace, Name: "benchmark"}
+	Expect(reconciler.reference(mgrCtx, export, gvks)).To(Succeed())
+	for _, gvk := range gvks {
+		// blocks until the informer of the kind is synced
+		_, err := mgr.GetCache().GetInformer(mgrCtx, metadataObject(gvk))
+		Expect(err).ToNot(HaveOccurred())
+	}
+	// every informer watches after its initial list
+	Eventually(func() int {
+		return len(requests.Watches())
+	}).Should(BeNumerically(">=", len(requests.Lists())))
+
+	return watchesMeasurement{
+		lists:   len(requests.Lists()),
+		watches: len(requests.Watches()),
+		heap:    heapAlloc() - before,
+	}
+}
+
+func heapAlloc() int64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return int64(stats.HeapAlloc)
+}
@@ -19,27 +19,30 @@ package resourcefieldexport
 import (
 	"context"
 	"fmt"
+	"sync"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/builder" // Required for Watching
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/handler" // Required for Watching
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
-	"sigs.k8s.io/controller-runtime/pkg/predicate" // Required for Watching
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
-	gdpv1alpha1 "github.com/deliveryhero/field-exporter/api/v1alpha1"
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
 	"github.com/deliveryhero/field-exporter/internal/resourcemanager"
 )
 
 const (
-	readyCondition = "Ready"
-	fromKindField  = ".spec.from.kind"
-	fromNameField  = ".spec.from.name"
+	exportFinalizer = "gdp.deliveryhero.io/finalizer"
+	sourceField     = ".spec.source"
 )
 
 // Reconciler reconciles a ResourceFieldExport object
@@ -47,12 +50,27 @@ type Reconciler struct {
 	client.Client
 	Scheme  *runtime.Scheme
 	Manager *resourcemanager.ResourceManager
+	// ClusterGrantNamespace is the namespace whose FieldExportGrants allow exports to read from
+	// cluster scoped sources, they are denied when empty
+	ClusterGrantNamespace string
+
+	controller controller.Controller
+	cache      cache.Cache
+	watchesMu  sync.Mutex
+	// watched are the source kinds whose metadata is watched
+	watched map[schema.GroupVersionKind]struct{}
+	// references are the exports reading from every source kind
+	references map[schema.GroupVersionKind]map[types.NamespacedName]struct{}
 }
 
 //+kubebuilder:rbac:groups=gdp.deliveryhero.io,resources=resourcefieldexports,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=gdp.deliveryhero.io,resources=resourcefieldexports/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=gdp.deliveryhero.io,resources=resourcefieldexports/finalizers,verbs=update
-//+kubebuilder:rbac:groups=core,resources=configmaps;secrets,verbs=get;list;update;patch;watch
+//+kubebuilder:rbac:groups=gdp.deliveryhero.io,resources=fieldexportgrants,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=configmaps;secrets,verbs=get;list;create;update;patch;delete;watch
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get
+// the groups below are the resourcemanager.DefaultGroups, groups added with --allowed-groups are
+// granted through the aggregated source-reader-role
 //+kubebuilder:rbac:groups=alloydb.cnrm.cloud.google.com,resources=*,verbs=get;list;watch
 //+kubebuilder:rbac:groups=iam.cnrm.cloud.google.com,resources=*,verbs=get;list;watch
 //+kubebuilder:rbac:groups=redis.cnrm.cloud.google.com,resources=*,verbs=get;list;watch
@@ -61,140 +79,246 @@ type Reconciler struct {
 //+kubebuilder:rbac:groups=rds.services.k8s.aws,resources=*,verbs=get;list;watch
 //+kubebuilder:rbac:groups=elasticache.services.k8s.aws,resources=*,verbs=get;list;watch
 //+kubebuilder:rbac:groups=dynamodb.services.k8s.aws,resources=*,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cache.azure.com,resources=*,verbs=get;list;watch
+//+kubebuilder:rbac:groups=dbformysql.azure.com,resources=*,verbs=get;list;watch
+//+kubebuilder:rbac:groups=dbforpostgresql.azure.com,resources=*,verbs=get;list;watch
+//+kubebuilder:rbac:groups=documentdb.azure.com,resources=*,verbs=get;list;watch
+//+kubebuilder:rbac:groups=storage.azure.com,resources=*,verbs=get;list;watch
 
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
-	fieldExports := &gdpv1alpha1.ResourceFieldExport{}
+	fieldExports := &gdpv1beta1.ResourceFieldExport{}
 	err := r.Client.Get(ctx, client.ObjectKey{
 		Namespace: req.Namespace,
 		Name:      req.Name,
 	}, fieldExports)
 
 	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// the export is gone, its source kinds may no longer need to be watched
+			return ctrl.Result{}, r.reference(ctx, req.NamespacedName, nil)
+		}
 		logger.Error(err, "failed to get ResourceFieldExport")
-		return ctrl.Result{}, client.IgnoreNotFound(err)
+		return ctrl.Result{}, err
 	}
 
-	fromResource := fieldExports.Spec.From
-	group, version, err := groupVersion(fromResource)
-	if err != nil {
-		logger.Error(err, "failed to parse group and version from resource",
-			"apiVersion", fromResource.APIVersion)
-		return r.degradedStatus(ctx, fieldExports, err)
+	if !fieldExports.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.finalize(ctx, fieldExports)
 	}
 
-	objectMap, err := r.resource(ctx, group, version, fromResource.Kind, fromResource.Name, req.Namespace)
-	if err != nil {
-		logger.Error(err, "failed to get source resource",
-			"kind", fromResource.Kind,
-			"name", fromResource.Name,
-			"namespace", req.Namespace)
-		return r.degradedStatus(ctx, fieldExports, err)
-	}
-
-	if fieldExports.Spec.RequiredFields != nil {
-		if err := verifyStatusConditions(ctx, objectMap, fieldExports.Spec.RequiredFields.StatusConditions); err != nil {
-			// This is not a fatal error, but a transient one. The resource is likely still being created.
-			// We log it as Info and requeue the request.
-			logger.Info("Required status conditions not met, will requeue", "reason", err.Error())
-			// We call degradedStatus to update the status on the CR, but we return a nil error
-			// to the controller-runtime. This prevents a scary "ERROR" log for what is a normal
-			// transient state (waiting for a resource to be ready). The result from degradedStatus
-			// will ensure we requeue.
-			res, _ := r.degradedStatus(ctx, fieldExports, err)
-			return res, nil
+	if err := r.reference(ctx, req.NamespacedName, sourceKinds(fieldExports.Spec)); err != nil {
+		logger.Error(err, "failed to watch source kinds")
+		return ctrl.Result{}, err
+	}
+
+	// the finalizer is only needed to clean up the destination, retained exports don't carry it
+	var finalizerUpdated bool
+	if fieldExports.Spec.DeletionPolicy == gdpv1beta1.Delete {
+		finalizerUpdated = controllerutil.AddFinalizer(fieldExports, exportFinalizer)
+	} else {
+		finalizerUpdated = controllerutil.RemoveFinalizer(fieldExports, exportFinalizer)
+	}
+	if finalizerUpdated {
+		if err := r.Update(ctx, fieldExports); err != nil {
+			logger.Error(err, "failed to update finalizers")
+			return ctrl.Result{}, err
 		}
 	}
 
-	cmValues := make(map[string]string)
-	for _, export := range fieldExports.Spec.Outputs {
-		value, err := fieldStringValue(ctx, objectMap, export.Path)
+	input := make(map[string]any)
+	for _, source := range exportSources(fieldExports.Spec) {
+		group, version, err := groupVersion(source.ResourceRef, r.Manager)
 		if err != nil {
-			logger.Error(err, "failed to extract field value",
-				"path", export.Path,
-				"key", export.Key)
-			return r.degradedStatus(ctx, fieldExports, err)
+			logger.Error(err, "failed to parse group and version from resource",
+				"apiVersion", source.APIVersion)
+			return r.degradedStatus(ctx, fieldExports, fieldExports.Status.Outputs, gdpv1beta1.ReasonSourceNotFound, err)
+		}
+
+		gk := schema.GroupKind{Group: group, Kind: source.Kind}
+		namespaced, err := r.IsObjectNamespaced(metadataObject(gk.WithVersion(version)))
+		if err != nil {
+			logger.Error(err, "failed to get the scope of the source kind",
+				"apiVersion", source.APIVersion,
+				"kind", source.Kind)
+			return r.degradedStatus(ctx, fieldExports, fieldExports.Status.Outputs, gdpv1beta1.ReasonSourceNotFound, err)
+		}
+		grantedBy, err := grantNamespace(fieldExports, source.ResourceRef, gk, namespaced, r.ClusterGrantNamespace)
+		if err != nil {
+			return r.notPermittedStatus(ctx, fieldExports, err)
+		}
+		if err := r.permitted(ctx, fieldExports, grantedBy, gk, source.Name); err != nil {
+			return r.notPermittedStatus(ctx, fieldExports, err)
 		}
-		cmValues[export.Key] = value
-	}
 
-	switch fieldExports.Spec.To.Type {
-	case gdpv1alpha1.Secret:
-		err = r.writeToSecret(ctx, fieldExports.Spec.To.Name, req.Namespace, cmValues)
-	case gdpv1alpha1.ConfigMap:
-		err = r.writeToConfigMap(ctx, fieldExports.Spec.To.Name, req.Namespace, cmValues)
-	default:
-		return r.degradedStatus(ctx, fieldExports, fmt.Errorf("unsupported destination type: %s", fieldExports.Spec.To.Type))
+		namespace := sourceNamespace(fieldExports, source.ResourceRef)
+
+		objectMap, err := r.resource(ctx, group, version, source.Kind, source.Name, namespace)
+		if err != nil {
+			logger.Error(err, "failed to get source resource",
+				"kind", source.Kind,
+				"name", source.Name,
+				"namespace", namespace)
+			return r.degradedStatus(ctx, fieldExports, fieldExports.Status.Outputs, gdpv1beta1.ReasonSourceNotFound, err)
+		}
+
+		if conditions := requiredConditions(fieldExports.Spec, group); len(conditions) > 0 {
+			if err := verifyStatusConditions(ctx, objectMap, conditions); err != nil {
+				if source.Alias != "" {
+					err = fmt.Errorf("source %s: %w", source.Alias, err)
+				}
+				// This is not a fatal error, but a transient one. The resource is likely still being created.
+				// We log it as Info and requeue the request.
+				logger.Info("Required status conditions not met, will requeue", "reason", err.Error())
+				// We call degradedStatus to update the status on the CR, but we return a nil error
+				// to the controller-runtime. This prevents a scary "ERROR" log for what is a normal
+				// transient state (waiting for a resource to be ready). The result from degradedStatus
+				// will ensure we requeue.
+				res, _ := r.degradedStatus(ctx, fieldExports, fieldExports.Status.Outputs, gdpv1beta1.ReasonRequiredConditionsNotMet, err)
+				return res, nil
+			}
+		}
+
+		if source.Alias == "" {
+			input = objectMap
+		} else {
+			input[source.Alias] = objectMap
+		}
 	}
 
+	cmValues, fallbacks, results, err := resolveOutputs(ctx, input, fieldExports.Spec.Outputs)
+	if fieldExports.Spec.DryRun {
+		logger.Info("dry run, outputs are previewed without writing", "type", fieldExports.Spec.To.Type, "name", fieldExports.Spec.To.Name)
+		outputs := outputStatuses(fieldExports.UID, fieldExports.Status.Outputs, results, false)
+		return r.previewStatus(ctx, fieldExports, previewEntries(fieldExports.UID, fieldExports.Spec.To.Type, results), outputs, err)
+	}
 	if err != nil {
+		outputs := outputStatuses(fieldExports.UID, fieldExports.Status.Outputs, results, false)
+		return r.degradedStatus(ctx, fieldExports, outputs, gdpv1beta1.ReasonQueryFailed, err)
+	}
+
+	destination := schema.GroupKind{Kind: string(fieldExports.Spec.To.Type)}
+	if err := r.permitted(ctx, fieldExports, destinationNamespace(fieldExports), destination, fieldExports.Spec.To.Name); err != nil {
+		return r.notPermittedStatus(ctx, fieldExports, err)
+	}
+
+	if err := r.writeDestination(ctx, fieldExports, cmValues); err != nil {
 		logger.Error(err, "failed to write to destination",
 			"type", fieldExports.Spec.To.Type,
 			"name", fieldExports.Spec.To.Name)
-		return r.degradedStatus(ctx, fieldExports, fmt.Errorf("failed to write to destination: %s", err))
+		outputs := outputStatuses(fieldExports.UID, fieldExports.Status.Outputs, results, false)
+		return r.degradedStatus(ctx, fieldExports, outputs, gdpv1beta1.ReasonDestinationWriteFailed, fmt.Errorf("failed to write to destination: %s", err))
 	}
 	logger.Info("output written to", "type", fieldExports.Spec.To.Type, "name", fieldExports.Spec.To.Name)
 
-	return r.readyStatus(ctx, fieldExports)
+	// the keys written to the destination `to` referred to before are removed once the current
+	// one holds them
+	exportedTo := exportedDestination(fieldExports)
+	if previous := fieldExports.Status.ExportedTo; previous != nil && *previous != exportedTo {
+		if err := r.releaseDestination(ctx, fieldExports, *previous); err != nil {
+			logger.Error(err, "failed to remove the exported keys from the previous destination",
+				"type", previous.Type,
+				"name", previous.Name,
+				"namespace", previous.Namespace)
+			outputs := outputStatuses(fieldExports.UID, fieldExports.Status.Outputs, results, true)
+			return r.degradedStatus(ctx, fieldExports, outputs, gdpv1beta1.ReasonDestinationWriteFailed,
+				fmt.Errorf("failed to remove the exported keys from %s %s: %s", previous.Type, previous.Name, err))
+		}
+	}
+
+	outputs := outputStatuses(fieldExports.UID, fieldExports.Status.Outputs, results, true)
+	return r.readyStatus(ctx, fieldExports, exportedKeys(cmValues), exportedTo, fallbacks, outputs)
+}
+
+// finalize applies the deletion policy of a deleted export and releases its finalizer.
+func (r *Reconciler) finalize(ctx context.Context, fieldExports *gdpv1beta1.ResourceFieldExport) error {
+	logger := log.FromContext(ctx)
+	if !controllerutil.ContainsFinalizer(fieldExports, exportFinalizer) {
+		return nil
+	}
+	if fieldExports.Spec.DeletionPolicy == gdpv1beta1.Delete {
+		if err := r.cleanupDestination(ctx, fieldExports); err != nil {
+			logger.Error(err, "failed to clean up destination",
+				"type", fieldExports.Spec.To.Type,
+				"name", fieldExports.Spec.To.Name)
+			return err
+		}
+	}
+	controllerutil.RemoveFinalizer(fieldExports, exportFinalizer)
+	if err := r.Update(ctx, fieldExports); err != nil {
+		logger.Error(err, "failed to remove finalizer")
+		return err
+	}
+	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
-	// index the kind field
-	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &gdpv1alpha1.ResourceFieldExport{}, fromKindField, func(rawObj client.Object) []string {
-		// Extract the from-resource kind field from the ResourceFieldExport Spec, if one is provided
-		resourceFieldExport := rawObj.(*gdpv1alpha1.ResourceFieldExport)
-		if resourceFieldExport.Spec.From.Kind == "" {
-			return nil
-		}
-		return []string{resourceFieldExport.Spec.From.Kind}
+	// index the kind and name of every source
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &gdpv1beta1.ResourceFieldExport{}, sourceField, func(rawObj client.Object) []string {
+		return sourceIndexValues(rawObj.(*gdpv1beta1.ResourceFieldExport))
 	}); err != nil {
 		return err
 	}
-
-	// index the name field
-	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &gdpv1alpha1.ResourceFieldExport{}, fromNameField, func(rawObj client.Object) []string {
-		// Extract the from-resource name field from the ResourceFieldExport Spec, if one is provided
-		resourceFieldExport := rawObj.(*gdpv1alpha1.ResourceFieldExport)
-		if resourceFieldExport.Spec.From.Name == "" {
-			return nil
-		}
-		return []string{resourceFieldExport.Spec.From.Name}
+	// index the other namespaces every export refers to, to find the exports a grant applies to
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &gdpv1beta1.ResourceFieldExport{}, namespaceField, func(rawObj client.Object) []string {
+		return referencedNamespaces(rawObj.(*gdpv1beta1.ResourceFieldExport))
 	}); err != nil {
 		return err
 	}
 
-	controllerBuilder := ctrl.NewControllerManagedBy(mgr).For(&gdpv1alpha1.ResourceFieldExport{})
-	controllerBuilder = r.setupWatches(controllerBuilder)
-	return controllerBuilder.Complete(r)
+	// destinations created by the controller are owned by the export, so changes to them
+	// (e.g. an accidental deletion) trigger a reconcile of their owner
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		For(&gdpv1beta1.ResourceFieldExport{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&corev1.Secret{}).
+		Watches(&gdpv1beta1.FieldExportGrant{}, handler.EnqueueRequestsFromMapFunc(r.findGrantedExports)).
+		Build(r)
+	if err != nil {
+		return err
+	}
+	// source kinds are watched once an export reads from them, see reference
+	r.controller = c
+	r.cache = mgr.GetCache()
+	r.watched = make(map[schema.GroupVersionKind]struct{})
+	r.references = make(map[schema.GroupVersionKind]map[types.NamespacedName]struct{})
+	return nil
 }
 
 func (r *Reconciler) findFieldExports(ctx context.Context, obj client.Object) []reconcile.Request {
 	logger := log.FromContext(ctx)
-	exportList := &gdpv1alpha1.ResourceFieldExportList{}
-
-	// Filter by both kind and name for better efficiency
 	kind := obj.GetObjectKind().GroupVersionKind().Kind
-	err := r.List(ctx, exportList, client.MatchingFields{
-		fromKindField: kind,
-		fromNameField: obj.GetName(),
-	}, client.InNamespace(obj.GetNamespace()))
-	if err != nil {
-		logger.Error(err, "failed to list ResourceFieldExports for watch trigger",
-			"sourceKind", kind,
-			"sourceName", obj.GetName(),
-			"namespace", obj.GetNamespace())
-		return nil
+
+	// exports of the namespace of the source refer to it by kind and name, exports of other
+	// namespaces by its namespace too
+	listOptions := [][]client.ListOption{{
+		client.MatchingFields{sourceField: sourceIndexValue(kind, obj.GetName())},
+		client.InNamespace(obj.GetNamespace()),
+	}}
+	if obj.GetNamespace() != "" {
+		listOptions = append(listOptions, []client.ListOption{client.MatchingFields{
+			sourceField: namespacedSourceIndexValue(obj.GetNamespace(), kind, obj.GetName()),
+		}})
 	}
-	requests := make([]reconcile.Request, 0, len(exportList.Items))
-	for _, exp := range exportList.Items {
-		requests = append(requests, reconcile.Request{
-			NamespacedName: types.NamespacedName{
-				Namespace: exp.Namespace,
-				Name:      exp.Name,
-			},
-		})
+	var requests []reconcile.Request
+	for _, opts := range listOptions {
+		exportList := &gdpv1beta1.ResourceFieldExportList{}
+		if err := r.List(ctx, exportList, opts...); err != nil {
+			logger.Error(err, "failed to list ResourceFieldExports for watch trigger",
+				"sourceKind", kind,
+				"sourceName", obj.GetName(),
+				"namespace", obj.GetNamespace())
+			return nil
+		}
+		for _, exp := range exportList.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: exp.Namespace,
+					Name:      exp.Name,
+				},
+			})
+		}
 	}
 	return requests
 }
@@ -215,16 +339,3 @@ func (r *Reconciler) resource(ctx context.Context, group, version, kind, name, n
 	}
 	return u.Object, nil
 }
-
-func (r *Reconciler) setupWatches(controllerBuilder *ctrl.Builder) *ctrl.Builder {
-	for _, resourceGVK := range r.Manager.Resources() {
-		unstructuredResource := &unstructured.Unstructured{}
-		unstructuredResource.SetGroupVersionKind(resourceGVK)
-		controllerBuilder = controllerBuilder.WatchesMetadata(
-			unstructuredResource,
-			handler.EnqueueRequestsFromMapFunc(r.findFieldExports),
-			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
-		)
-	}
-	return controllerBuilder
-}
@@ -10,6 +10,9 @@ import (
 
 	redisv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/redis/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	apimetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -17,10 +20,11 @@ import (
 	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/utils/ptr"
 	cr "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	gdpv1alpha1 "github.com/deliveryhero/field-exporter/api/v1alpha1"
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
 )
 
 var _ = Describe("ResourceFieldExport controller", func() {
@@ -80,22 +84,22 @@ var _ = Describe("ResourceFieldExport controller", func() {
 		When("creating a field export", func() {
 			It("should succeed", func() {
 				ctx := context.Background()
-				rfe := &gdpv1alpha1.ResourceFieldExport{
+				rfe := &gdpv1beta1.ResourceFieldExport{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test",
 						Namespace: testNamespace,
 					},
-					Spec: gdpv1alpha1.ResourceFieldExportSpec{
-						From: gdpv1alpha1.ResourceRef{
+					Spec: gdpv1beta1.ResourceFieldExportSpec{
+						From: &gdpv1beta1.ResourceRef{
 							APIVersion: redisv1beta1.RedisInstanceGVK.GroupVersion().String(),
 							Kind:       redisv1beta1.RedisInstanceGVK.Kind,
 							Name:       "redis-instance",
 						},
-						To: gdpv1alpha1.DestinationRef{
-							Type: gdpv1alpha1.ConfigMap,
+						To: gdpv1beta1.DestinationRef{
+							Type: gdpv1beta1.ConfigMap,
 							Name: "target-cm",
 						},
-						Outputs: []gdpv1alpha1.Output{
+						Outputs: []gdpv1beta1.Output{
 							{
 								Key:  "display-name",
 								Path: ".spec.displayName",
@@ -118,22 +122,22 @@ var _ = Describe("ResourceFieldExport controller", func() {
 		When("source resource is updated", func() {
 			BeforeEach(func() {
 				ctx := context.Background()
-				rfe := &gdpv1alpha1.ResourceFieldExport{
+				rfe := &gdpv1beta1.ResourceFieldExport{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test",
 						Namespace: testNamespace,
 					},
-					Spec: gdpv1alpha1.ResourceFieldExportSpec{
-						From: gdpv1alpha1.ResourceRef{
+					Spec: gdpv1beta1.ResourceFieldExportSpec{
+						From: &gdpv1beta1.ResourceRef{
 							APIVersion: redisv1beta1.RedisInstanceGVK.GroupVersion().String(),
 							Kind:       redisv1beta1.RedisInstanceGVK.Kind,
 							Name:       "redis-instance",
 						},
-						To: gdpv1alpha1.DestinationRef{
-							Type: gdpv1alpha1.ConfigMap,
+						To: gdpv1beta1.DestinationRef{
+							Type: gdpv1beta1.ConfigMap,
 							Name: "target-cm",
 						},
-						Outputs: []gdpv1alpha1.Output{
+						Outputs: []gdpv1beta1.Output{
 							{
 								Key:  "display-name",
 								Path: ".spec.displayName",
@@ -165,6 +169,743 @@ var _ = Describe("ResourceFieldExport controller", func() {
 		})
 	})
 
+	Context("for a destination shared with other field managers", func() {
+		var rfe *gdpv1beta1.ResourceFieldExport
+
+		BeforeEach(func() {
+			rfe = &gdpv1beta1.ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-apply",
+					Namespace: testNamespace,
+				},
+				Spec: gdpv1beta1.ResourceFieldExportSpec{
+					From: &gdpv1beta1.ResourceRef{
+						APIVersion: redisv1beta1.RedisInstanceGVK.GroupVersion().String(),
+						Kind:       redisv1beta1.RedisInstanceGVK.Kind,
+						Name:       "redis-instance",
+					},
+					To: gdpv1beta1.DestinationRef{
+						Type: gdpv1beta1.ConfigMap,
+						Name: "target-cm",
+					},
+					Outputs: []gdpv1beta1.Output{
+						{
+							Key:  "display-name",
+							Path: ".spec.displayName",
+						},
+					},
+				},
+			}
+		})
+
+		It("should own the exported keys in managedFields", func() {
+			ctx := context.Background()
+			Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+
+			Eventually(func() []string {
+				cm := &corev1.ConfigMap{}
+				_ = k8sClient.Get(context.Background(), cr.ObjectKey{Namespace: testNamespace, Name: "target-cm"}, cm)
+				managers := make([]string, 0, len(cm.ManagedFields))
+				for _, f := range cm.ManagedFields {
+					if f.Operation == metav1.ManagedFieldsOperationApply {
+						managers = append(managers, f.Manager)
+					}
+				}
+				return managers
+			}, "10s").Should(ContainElement("field-exporter/test-apply"))
+		})
+
+		It("should report conflicts in the status", func() {
+			ctx := context.Background()
+			gitOpsApplied := &corev1.ConfigMap{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+				ObjectMeta: metav1.ObjectMeta{Name: "target-cm", Namespace: testNamespace},
+				Data:       map[string]string{"display-name": "managed-by-gitops"},
+			}
+			Expect(k8sClient.Patch(ctx, gitOpsApplied, cr.Apply, cr.FieldOwner("gitops"))).Should(Succeed())
+			Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+
+			Eventually(func() string {
+				updatedRfe := &gdpv1beta1.ResourceFieldExport{}
+				_ = k8sClient.Get(context.Background(), cr.ObjectKeyFromObject(rfe), updatedRfe)
+				if len(updatedRfe.Status.Conditions) > 0 {
+					return updatedRfe.Status.Conditions[0].Message
+				}
+				return ""
+			}, "10s").Should(ContainSubstring("keys of ConfigMap target-cm are managed by another field manager"))
+		})
+	})
+
+	Context("for renamed outputs", func() {
+		It("should remove stale keys and keep foreign ones", func() {
+			ctx := context.Background()
+			cm := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, cr.ObjectKey{Namespace: testNamespace, Name: "target-cm"}, cm)).Should(Succeed())
+			cm.Data = map[string]string{"foreign": "value"}
+			Expect(k8sClient.Update(ctx, cm)).Should(Succeed())
+
+			rfe := &gdpv1beta1.ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-rename",
+					Namespace: testNamespace,
+				},
+				Spec: gdpv1beta1.ResourceFieldExportSpec{
+					From: &gdpv1beta1.ResourceRef{
+						APIVersion: redisv1beta1.RedisInstanceGVK.GroupVersion().String(),
+						Kind:       redisv1beta1.RedisInstanceGVK.Kind,
+						Name:       "redis-instance",
+					},
+					To: gdpv1beta1.DestinationRef{
+						Type: gdpv1beta1.ConfigMap,
+						Name: "target-cm",
+					},
+					Outputs: []gdpv1beta1.Output{
+						{
+							Key:  "display-name",
+							Path: ".spec.displayName",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+			Eventually(func() []string {
+				updatedRfe := &gdpv1beta1.ResourceFieldExport{}
+				_ = k8sClient.Get(context.Background(), cr.ObjectKeyFromObject(rfe), updatedRfe)
+				return updatedRfe.Status.ExportedKeys
+			}, "10s").Should(Equal([]string{"display-name"}))
+
+			Expect(k8sClient.Get(ctx, cr.ObjectKeyFromObject(rfe), rfe)).Should(Succeed())
+			rfe.Spec.Outputs[0].Key = "name"
+			Expect(k8sClient.Update(ctx, rfe)).Should(Succeed())
+
+			Eventually(func() map[string]string {
+				cm := &corev1.ConfigMap{}
+				_ = k8sClient.Get(context.Background(), cr.ObjectKey{Namespace: testNamespace, Name: "target-cm"}, cm)
+				return cm.Data
+			}, "10s").Should(Equal(map[string]string{
+				"foreign": "value",
+				"name":    "test-0001-testdb-default",
+			}))
+		})
+
+		It("should move the keys to a new destination and keep the keys it didn't write", func() {
+			ctx := context.Background()
+			cm := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, cr.ObjectKey{Namespace: testNamespace, Name: "target-cm"}, cm)).Should(Succeed())
+			cm.Data = map[string]string{"foreign": "value"}
+			Expect(k8sClient.Update(ctx, cm)).Should(Succeed())
+			// the new destination has a key of the same name, written by another tool
+			Expect(k8sClient.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "other-cm", Namespace: testNamespace},
+				Data:       map[string]string{"display-name": "helm"},
+			})).Should(Succeed())
+
+			rfe := &gdpv1beta1.ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-move",
+					Namespace: testNamespace,
+				},
+				Spec: gdpv1beta1.ResourceFieldExportSpec{
+					From: &gdpv1beta1.ResourceRef{
+						APIVersion: redisv1beta1.RedisInstanceGVK.GroupVersion().String(),
+						Kind:       redisv1beta1.RedisInstanceGVK.Kind,
+						Name:       "redis-instance",
+					},
+					To: gdpv1beta1.DestinationRef{
+						Type: gdpv1beta1.ConfigMap,
+						Name: "target-cm",
+					},
+					Outputs: []gdpv1beta1.Output{
+						{
+							Key:  "display-name",
+							Path: ".spec.displayName",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+			Eventually(func() []string {
+				_ = k8sClient.Get(ctx, cr.ObjectKeyFromObject(rfe), rfe)
+				return rfe.Status.ExportedKeys
+			}, "10s").Should(Equal([]string{"display-name"}))
+			Expect(rfe.Status.ExportedTo).Should(Equal(&gdpv1beta1.ExportedDestination{
+				Type: gdpv1beta1.ConfigMap, Name: "target-cm", Namespace: testNamespace,
+			}))
+
+			// the key is renamed while the destination changes
+			rfe.Spec.To.Name = "other-cm"
+			rfe.Spec.Outputs[0].Key = "name"
+			Expect(k8sClient.Update(ctx, rfe)).Should(Succeed())
+
+			configMapData := func(name string) func() map[string]string {
+				return func() map[string]string {
+					cm := &corev1.ConfigMap{}
+					_ = k8sClient.Get(ctx, cr.ObjectKey{Namespace: testNamespace, Name: name}, cm)
+					return cm.Data
+				}
+			}
+			Eventually(configMapData("other-cm"), "10s").Should(Equal(map[string]string{
+				"display-name": "helm",
+				"name":         "test-0001-testdb-default",
+			}))
+			Eventually(configMapData("target-cm"), "10s").Should(Equal(map[string]string{"foreign": "value"}))
+			Eventually(func() *gdpv1beta1.ExportedDestination {
+				_ = k8sClient.Get(ctx, cr.ObjectKeyFromObject(rfe), rfe)
+				return rfe.Status.ExportedTo
+			}, "10s").Should(HaveField("Name", "other-cm"))
+		})
+	})
+
+	Context("when deleting a field export", func() {
+		var rfe *gdpv1beta1.ResourceFieldExport
+
+		BeforeEach(func() {
+			ctx := context.Background()
+			cm := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, cr.ObjectKey{Namespace: testNamespace, Name: "target-cm"}, cm)).Should(Succeed())
+			cm.Data = map[string]string{"foreign": "value"}
+			Expect(k8sClient.Update(ctx, cm)).Should(Succeed())
+
+			rfe = &gdpv1beta1.ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-delete",
+					Namespace: testNamespace,
+				},
+				Spec: gdpv1beta1.ResourceFieldExportSpec{
+					From: &gdpv1beta1.ResourceRef{
+						APIVersion: redisv1beta1.RedisInstanceGVK.GroupVersion().String(),
+						Kind:       redisv1beta1.RedisInstanceGVK.Kind,
+						Name:       "redis-instance",
+					},
+					To: gdpv1beta1.DestinationRef{
+						Type: gdpv1beta1.ConfigMap,
+						Name: "target-cm",
+					},
+					Outputs: []gdpv1beta1.Output{
+						{
+							Key:  "display-name",
+							Path: ".spec.displayName",
+						},
+					},
+				},
+			}
+		})
+
+		exportedData := func() map[string]string {
+			cm := &corev1.ConfigMap{}
+			_ = k8sClient.Get(context.Background(), cr.ObjectKey{Namespace: testNamespace, Name: "target-cm"}, cm)
+			return cm.Data
+		}
+
+		When("deletion policy is Delete", func() {
+			It("should remove the exported keys", func() {
+				ctx := context.Background()
+				rfe.Spec.DeletionPolicy = gdpv1beta1.Delete
+				Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+				Eventually(func() []string {
+					updatedRfe := &gdpv1beta1.ResourceFieldExport{}
+					_ = k8sClient.Get(context.Background(), cr.ObjectKeyFromObject(rfe), updatedRfe)
+					return updatedRfe.Status.ExportedKeys
+				}, "10s").Should(Equal([]string{"display-name"}))
+
+				Expect(k8sClient.Delete(ctx, rfe)).Should(Succeed())
+				Eventually(func() bool {
+					return apierrors.IsNotFound(k8sClient.Get(context.Background(), cr.ObjectKeyFromObject(rfe), rfe))
+				}, "10s").Should(BeTrue())
+				Expect(exportedData()).Should(Equal(map[string]string{"foreign": "value"}))
+			})
+
+			It("should not remove the keys of a destination in a namespace that doesn't grant it", func() {
+				ctx := context.Background()
+				victimNamespace := testNamespace + "-victim"
+				Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: victimNamespace}})).Should(Succeed())
+				victim := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "target-cm", Namespace: victimNamespace},
+					Data:       map[string]string{"display-name": "victim"},
+				}
+				Expect(k8sClient.Create(ctx, victim)).Should(Succeed())
+
+				rfe.Spec.DeletionPolicy = gdpv1beta1.Delete
+				Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+				Eventually(func() []string {
+					_ = k8sClient.Get(ctx, cr.ObjectKeyFromObject(rfe), rfe)
+					return rfe.Status.ExportedKeys
+				}, "10s").Should(Equal([]string{"display-name"}))
+
+				// the destination is pointed at another namespace after the keys were written
+				rfe.Spec.To.Namespace = victimNamespace
+				Expect(k8sClient.Update(ctx, rfe)).Should(Succeed())
+				Expect(k8sClient.Delete(ctx, rfe)).Should(Succeed())
+				Eventually(func() bool {
+					return apierrors.IsNotFound(k8sClient.Get(ctx, cr.ObjectKeyFromObject(rfe), rfe))
+				}, "10s").Should(BeTrue())
+
+				Expect(k8sClient.Get(ctx, cr.ObjectKeyFromObject(victim), victim)).Should(Succeed())
+				Expect(victim.Data).Should(Equal(map[string]string{"display-name": "victim"}))
+			})
+		})
+
+		When("deletion policy is Retain", func() {
+			It("should keep the exported keys", func() {
+				ctx := context.Background()
+				Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+				Eventually(exportedData, "10s").Should(HaveKeyWithValue("display-name", "test-0001-testdb-default"))
+
+				Expect(k8sClient.Delete(ctx, rfe)).Should(Succeed())
+				Eventually(func() bool {
+					return apierrors.IsNotFound(k8sClient.Get(context.Background(), cr.ObjectKeyFromObject(rfe), rfe))
+				}, "10s").Should(BeTrue())
+				Expect(exportedData()).Should(HaveKeyWithValue("display-name", "test-0001-testdb-default"))
+			})
+		})
+	})
+
+	Context("for a missing destination", func() {
+		var rfe *gdpv1beta1.ResourceFieldExport
+
+		BeforeEach(func() {
+			rfe = &gdpv1beta1.ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-create",
+					Namespace: testNamespace,
+				},
+				Spec: gdpv1beta1.ResourceFieldExportSpec{
+					From: &gdpv1beta1.ResourceRef{
+						APIVersion: redisv1beta1.RedisInstanceGVK.GroupVersion().String(),
+						Kind:       redisv1beta1.RedisInstanceGVK.Kind,
+						Name:       "redis-instance",
+					},
+					To: gdpv1beta1.DestinationRef{
+						Type: gdpv1beta1.Secret,
+						Name: "generated-secret",
+					},
+					Outputs: []gdpv1beta1.Output{
+						{
+							Key:  "display-name",
+							Path: ".spec.displayName",
+						},
+					},
+				},
+			}
+		})
+
+		When("create policy is CreateIfMissing", func() {
+			It("should create the destination owned by the export", func() {
+				ctx := context.Background()
+				rfe.Spec.To.CreatePolicy = gdpv1beta1.CreateIfMissing
+				Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+
+				secret := &corev1.Secret{}
+				Eventually(func() error {
+					return k8sClient.Get(context.Background(), cr.ObjectKey{Namespace: testNamespace, Name: "generated-secret"}, secret)
+				}, "10s").Should(Succeed())
+				Expect(secret.Data).Should(HaveKeyWithValue("display-name", []byte("test-0001-testdb-default")))
+				Expect(secret.OwnerReferences).Should(HaveLen(1))
+				Expect(secret.OwnerReferences[0].Name).Should(Equal(rfe.Name))
+				Expect(*secret.OwnerReferences[0].Controller).Should(BeTrue())
+			})
+		})
+
+		When("create policy is MustExist", func() {
+			It("should not create the destination", func() {
+				ctx := context.Background()
+				Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+
+				Eventually(func() metav1.ConditionStatus {
+					updatedRfe := &gdpv1beta1.ResourceFieldExport{}
+					_ = k8sClient.Get(context.Background(), cr.ObjectKeyFromObject(rfe), updatedRfe)
+					if len(updatedRfe.Status.Conditions) > 0 {
+						return updatedRfe.Status.Conditions[0].Status
+					}
+					return metav1.ConditionUnknown
+				}, "10s").Should(Equal(metav1.ConditionFalse))
+				err := k8sClient.Get(ctx, cr.ObjectKey{Namespace: testNamespace, Name: "generated-secret"}, &corev1.Secret{})
+				Expect(apierrors.IsNotFound(err)).Should(BeTrue())
+			})
+		})
+
+		When("the destination already exists", func() {
+			It("should not take ownership of it", func() {
+				ctx := context.Background()
+				Expect(k8sClient.Create(ctx, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "generated-secret", Namespace: testNamespace},
+				})).Should(Succeed())
+				rfe.Spec.To.CreatePolicy = gdpv1beta1.CreateIfMissing
+				Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+
+				secret := &corev1.Secret{}
+				Eventually(func() map[string][]byte {
+					_ = k8sClient.Get(context.Background(), cr.ObjectKey{Namespace: testNamespace, Name: "generated-secret"}, secret)
+					return secret.Data
+				}, "10s").Should(HaveKeyWithValue("display-name", []byte("test-0001-testdb-default")))
+				Expect(secret.OwnerReferences).Should(BeEmpty())
+			})
+		})
+	})
+
+	Context("for templated outputs", func() {
+		It("should compose the value from several fields", func() {
+			ctx := context.Background()
+			rfe := &gdpv1beta1.ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-template",
+					Namespace: testNamespace,
+				},
+				Spec: gdpv1beta1.ResourceFieldExportSpec{
+					From: &gdpv1beta1.ResourceRef{
+						APIVersion: redisv1beta1.RedisInstanceGVK.GroupVersion().String(),
+						Kind:       redisv1beta1.RedisInstanceGVK.Kind,
+						Name:       "redis-instance",
+					},
+					To: gdpv1beta1.DestinationRef{
+						Type: gdpv1beta1.ConfigMap,
+						Name: "target-cm",
+					},
+					Outputs: []gdpv1beta1.Output{
+						{
+							Key:      "description",
+							Template: `{{ .spec.displayName }} ({{ .spec.redisVersion | lower }})`,
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+
+			Eventually(func() map[string]string {
+				cm := &corev1.ConfigMap{}
+				_ = k8sClient.Get(context.Background(), cr.ObjectKey{Namespace: testNamespace, Name: "target-cm"}, cm)
+				return cm.Data
+			}, "10s").Should(HaveKeyWithValue("description", "test-0001-testdb-default (redis_6_x)"))
+		})
+	})
+
+	Context("for formatted outputs", func() {
+		It("should render the fields into a single key", func() {
+			ctx := context.Background()
+			rfe := &gdpv1beta1.ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-format",
+					Namespace: testNamespace,
+				},
+				Spec: gdpv1beta1.ResourceFieldExportSpec{
+					From: &gdpv1beta1.ResourceRef{
+						APIVersion: redisv1beta1.RedisInstanceGVK.GroupVersion().String(),
+						Kind:       redisv1beta1.RedisInstanceGVK.Kind,
+						Name:       "redis-instance",
+					},
+					To: gdpv1beta1.DestinationRef{
+						Type: gdpv1beta1.ConfigMap,
+						Name: "target-cm",
+					},
+					Outputs: []gdpv1beta1.Output{
+						{
+							Key:    "application.yaml",
+							Format: gdpv1beta1.YAML,
+							Fields: []gdpv1beta1.Field{
+								{Key: "name", Path: ".spec.displayName"},
+								{Key: "memorySizeGb", Path: ".spec.memorySizeGb"},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+
+			Eventually(func() map[string]string {
+				cm := &corev1.ConfigMap{}
+				_ = k8sClient.Get(context.Background(), cr.ObjectKey{Namespace: testNamespace, Name: "target-cm"}, cm)
+				return cm.Data
+			}, "10s").Should(HaveKeyWithValue("application.yaml", "memorySizeGb: 5\nname: test-0001-testdb-default\n"))
+		})
+	})
+
+	Context("for outputs of missing fields", func() {
+		It("should fall back and report the fallbacks", func() {
+			ctx := context.Background()
+			rfe := &gdpv1beta1.ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-fallback",
+					Namespace: testNamespace,
+				},
+				Spec: gdpv1beta1.ResourceFieldExportSpec{
+					From: &gdpv1beta1.ResourceRef{
+						APIVersion: redisv1beta1.RedisInstanceGVK.GroupVersion().String(),
+						Kind:       redisv1beta1.RedisInstanceGVK.Kind,
+						Name:       "redis-instance",
+					},
+					To: gdpv1beta1.DestinationRef{
+						Type: gdpv1beta1.ConfigMap,
+						Name: "target-cm",
+					},
+					Outputs: []gdpv1beta1.Output{
+						{
+							Key:  "display-name",
+							Path: ".spec.displayName",
+						},
+						{
+							Key:     "read-endpoint-port",
+							Path:    ".status.readEndpointPort",
+							Default: ptr.To("6379"),
+						},
+						{
+							Key:      "read-endpoint",
+							Path:     ".status.readEndpoint",
+							Optional: true,
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+
+			cm := &corev1.ConfigMap{}
+			Eventually(func() map[string]string {
+				_ = k8sClient.Get(context.Background(), cr.ObjectKey{Namespace: testNamespace, Name: "target-cm"}, cm)
+				return cm.Data
+			}, "10s").Should(HaveKeyWithValue("read-endpoint-port", "6379"))
+			Expect(cm.Data).Should(HaveKeyWithValue("display-name", "test-0001-testdb-default"))
+			Expect(cm.Data).ShouldNot(HaveKey("read-endpoint"))
+
+			Eventually(func() []gdpv1beta1.OutputFallback {
+				_ = k8sClient.Get(ctx, cr.ObjectKeyFromObject(rfe), rfe)
+				return rfe.Status.Fallbacks
+			}, "10s").Should(ConsistOf(
+				gdpv1beta1.OutputFallback{Key: "read-endpoint-port", Policy: gdpv1beta1.NullDefault},
+				gdpv1beta1.OutputFallback{Key: "read-endpoint", Policy: gdpv1beta1.NullSkip},
+			))
+		})
+	})
+
+	Context("for outputs with broken paths", func() {
+		It("should report the state of every output", func() {
+			ctx := context.Background()
+			rfe := &gdpv1beta1.ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-output-status",
+					Namespace: testNamespace,
+				},
+				Spec: gdpv1beta1.ResourceFieldExportSpec{
+					From: &gdpv1beta1.ResourceRef{
+						APIVersion: redisv1beta1.RedisInstanceGVK.GroupVersion().String(),
+						Kind:       redisv1beta1.RedisInstanceGVK.Kind,
+						Name:       "redis-instance",
+					},
+					To: gdpv1beta1.DestinationRef{
+						Type: gdpv1beta1.ConfigMap,
+						Name: "target-cm",
+					},
+					Outputs: []gdpv1beta1.Output{
+						{
+							Key:  "display-name",
+							Path: ".spec.displayName",
+						},
+						{
+							Key:  "host",
+							Path: ".status.host",
+						},
+						{
+							Key:  "port",
+							Path: ".status.port",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+
+			Eventually(func() []gdpv1beta1.OutputStatus {
+				_ = k8sClient.Get(ctx, cr.ObjectKeyFromObject(rfe), rfe)
+				return rfe.Status.Outputs
+			}, "10s").Should(HaveLen(3))
+			// nothing is written while an output fails
+			Expect(rfe.Status.Outputs[0].State).Should(Equal(gdpv1beta1.OutputPending))
+			Expect(rfe.Status.Outputs[1].State).Should(Equal(gdpv1beta1.OutputError))
+			Expect(rfe.Status.Outputs[1].LastError).Should(Equal("no results returned for query .status.host"))
+			Expect(rfe.Status.Outputs[2].State).Should(Equal(gdpv1beta1.OutputError))
+			Expect(rfe.Status.Outputs[2].LastError).Should(Equal("no results returned for query .status.port"))
+			Expect(rfe.Status.Conditions).Should(ConsistOf(And(
+				HaveField("Type", gdpv1beta1.ReadyCondition),
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", gdpv1beta1.ReasonQueryFailed),
+			)))
+
+			// fixing the paths syncs the outputs and records the hash of the written values
+			rfe.Spec.Outputs = rfe.Spec.Outputs[:1]
+			Expect(k8sClient.Update(ctx, rfe)).Should(Succeed())
+			Eventually(func() []gdpv1beta1.OutputStatus {
+				_ = k8sClient.Get(ctx, cr.ObjectKeyFromObject(rfe), rfe)
+				return rfe.Status.Outputs
+			}, "10s").Should(HaveLen(1))
+			Expect(rfe.Status.Outputs[0].State).Should(Equal(gdpv1beta1.OutputSynced))
+			Expect(rfe.Status.Outputs[0].Hash).Should(Equal(valueHash(rfe.UID, "test-0001-testdb-default")))
+			Expect(rfe.Status.Outputs[0].LastChanged).ShouldNot(BeNil())
+			Expect(rfe.Status.ObservedGeneration).Should(Equal(rfe.Generation))
+			Expect(rfe.Status.LastSyncTime).ShouldNot(BeNil())
+			Expect(rfe.Status.Conditions).Should(ConsistOf(And(
+				HaveField("Type", gdpv1beta1.ReadyCondition),
+				HaveField("Status", metav1.ConditionTrue),
+				HaveField("Reason", gdpv1beta1.ReasonSynced),
+				HaveField("ObservedGeneration", rfe.Generation),
+			)))
+		})
+	})
+
+	Context("for dry runs", func() {
+		It("should preview the outputs without writing the destination", func() {
+			ctx := context.Background()
+			rfe := &gdpv1beta1.ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-dry-run",
+					Namespace: testNamespace,
+				},
+				Spec: gdpv1beta1.ResourceFieldExportSpec{
+					From: &gdpv1beta1.ResourceRef{
+						APIVersion: redisv1beta1.RedisInstanceGVK.GroupVersion().String(),
+						Kind:       redisv1beta1.RedisInstanceGVK.Kind,
+						Name:       "redis-instance",
+					},
+					To: gdpv1beta1.DestinationRef{
+						Type: gdpv1beta1.ConfigMap,
+						Name: "target-cm",
+					},
+					Outputs: []gdpv1beta1.Output{
+						{
+							Key:  "display-name",
+							Path: ".spec.displayName",
+						},
+						{
+							Key:      "read-endpoint",
+							Path:     ".status.readEndpoint",
+							Optional: true,
+						},
+					},
+					DryRun: true,
+				},
+			}
+			Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+
+			Eventually(func() []gdpv1beta1.PreviewEntry {
+				_ = k8sClient.Get(ctx, cr.ObjectKeyFromObject(rfe), rfe)
+				return rfe.Status.Preview
+			}, "10s").Should(ConsistOf(gdpv1beta1.PreviewEntry{
+				Key:   "display-name",
+				Value: "test-0001-testdb-default",
+				Hash:  valueHash(rfe.UID, "test-0001-testdb-default"),
+			}))
+			Expect(rfe.Status.ExportedKeys).Should(BeEmpty())
+			Expect(rfe.Status.Conditions).Should(ConsistOf(And(
+				HaveField("Type", gdpv1beta1.ReadyCondition),
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", gdpv1beta1.ReasonDryRun),
+			)))
+			cm := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, cr.ObjectKey{Namespace: testNamespace, Name: "target-cm"}, cm)).Should(Succeed())
+			Expect(cm.Data).Should(BeEmpty())
+
+			// turning the dry run off writes the destination and drops the preview
+			rfe.Spec.DryRun = false
+			Expect(k8sClient.Update(ctx, rfe)).Should(Succeed())
+			Eventually(func() map[string]string {
+				_ = k8sClient.Get(ctx, cr.ObjectKey{Namespace: testNamespace, Name: "target-cm"}, cm)
+				return cm.Data
+			}, "10s").Should(HaveKeyWithValue("display-name", "test-0001-testdb-default"))
+			Eventually(func() []gdpv1beta1.PreviewEntry {
+				_ = k8sClient.Get(ctx, cr.ObjectKeyFromObject(rfe), rfe)
+				return rfe.Status.Preview
+			}, "10s").Should(BeEmpty())
+		})
+	})
+
+	Context("for multiple source resources", func() {
+		var awsDbCluster *unstructured.Unstructured
+
+		BeforeEach(func() {
+			ctx := context.Background()
+			awsDbCluster = &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "rds.services.k8s.aws/v1alpha1",
+					"kind":       "DBCluster",
+					"metadata": map[string]interface{}{
+						"name":      "aws-db-cluster",
+						"namespace": testNamespace,
+					},
+					"spec": map[string]interface{}{
+						"dbClusterIdentifier": "aws-db-cluster",
+						"engine":              "aurora-postgresql",
+						"masterUsername":      "testuser",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, awsDbCluster)).Should(Succeed())
+			awsDbCluster.Object["status"] = map[string]interface{}{
+				"endpoint": "my-cluster-writer.cluster-random.us-east-1.rds.amazonaws.com",
+			}
+			Expect(k8sClient.Status().Update(ctx, awsDbCluster)).Should(Succeed())
+
+			rfe := &gdpv1beta1.ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-sources",
+					Namespace: testNamespace,
+				},
+				Spec: gdpv1beta1.ResourceFieldExportSpec{
+					Sources: []gdpv1beta1.Source{
+						{
+							Alias: "cache",
+							ResourceRef: gdpv1beta1.ResourceRef{
+								APIVersion: redisv1beta1.RedisInstanceGVK.GroupVersion().String(),
+								Kind:       redisv1beta1.RedisInstanceGVK.Kind,
+								Name:       "redis-instance",
+							},
+						},
+						{
+							Alias: "db",
+							ResourceRef: gdpv1beta1.ResourceRef{
+								APIVersion: "rds.services.k8s.aws/v1alpha1",
+								Kind:       "DBCluster",
+								Name:       "aws-db-cluster",
+							},
+						},
+					},
+					To: gdpv1beta1.DestinationRef{
+						Type: gdpv1beta1.ConfigMap,
+						Name: "target-cm",
+					},
+					Outputs: []gdpv1beta1.Output{
+						{
+							Key:  "display-name",
+							Path: ".cache.spec.displayName",
+						},
+						{
+							Key:  "db-endpoint",
+							Path: ".db.status.endpoint",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+		})
+
+		exportedData := func() map[string]string {
+			cm := &corev1.ConfigMap{}
+			_ = k8sClient.Get(context.Background(), cr.ObjectKey{Namespace: testNamespace, Name: "target-cm"}, cm)
+			return cm.Data
+		}
+
+		It("should export fields of all sources", func() {
+			Eventually(exportedData, "10s").Should(And(
+				HaveKeyWithValue("display-name", "test-0001-testdb-default"),
+				HaveKeyWithValue("db-endpoint", "my-cluster-writer.cluster-random.us-east-1.rds.amazonaws.com"),
+			))
+		})
+
+		It("should update the target when any source changes", func() {
+			ctx := context.Background()
+			Eventually(exportedData, "10s").Should(HaveKey("db-endpoint"))
+
+			data := `{"status":{"endpoint":"new-writer.cluster-random.us-east-1.rds.amazonaws.com"}}`
+			Expect(k8sClient.Status().Patch(ctx, awsDbCluster, cr.RawPatch(types.MergePatchType, []byte(data)))).Should(Succeed())
+			Eventually(exportedData, "10s").Should(HaveKeyWithValue("db-endpoint", "new-writer.cluster-random.us-east-1.rds.amazonaws.com"))
+		})
+	})
+
 	Context("for existing source resource (AWS DBCluster)", func() {
 		var awsDbCluster *unstructured.Unstructured
 
@@ -199,22 +940,22 @@ var _ = Describe("ResourceFieldExport controller", func() {
 		When("creating a field export for an AWS DBCluster resource", func() {
 			It("should succeed and populate the target with cluster endpoints", func() {
 				ctx := context.Background()
-				rfe := &gdpv1alpha1.ResourceFieldExport{
+				rfe := &gdpv1beta1.ResourceFieldExport{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-aws-cluster",
 						Namespace: testNamespace,
 					},
-					Spec: gdpv1alpha1.ResourceFieldExportSpec{
-						From: gdpv1alpha1.ResourceRef{
+					Spec: gdpv1beta1.ResourceFieldExportSpec{
+						From: &gdpv1beta1.ResourceRef{
 							APIVersion: "rds.services.k8s.aws/v1alpha1",
 							Kind:       "DBCluster",
 							Name:       "aws-db-cluster",
 						},
-						To: gdpv1alpha1.DestinationRef{
-							Type: gdpv1alpha1.ConfigMap,
+						To: gdpv1beta1.DestinationRef{
+							Type: gdpv1beta1.ConfigMap,
 							Name: "target-cm",
 						},
-						Outputs: []gdpv1alpha1.Output{
+						Outputs: []gdpv1beta1.Output{
 							{
 								Key:  "cluster-writer-endpoint",
 								Path: ".status.endpoint",
@@ -275,22 +1016,22 @@ var _ = Describe("ResourceFieldExport controller", func() {
 		When("creating a field export for an AWS resource", func() {
 			It("should succeed and populate the target", func() {
 				ctx := context.Background()
-				rfe := &gdpv1alpha1.ResourceFieldExport{
+				rfe := &gdpv1beta1.ResourceFieldExport{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-aws",
 						Namespace: testNamespace,
 					},
-					Spec: gdpv1alpha1.ResourceFieldExportSpec{
-						From: gdpv1alpha1.ResourceRef{
+					Spec: gdpv1beta1.ResourceFieldExportSpec{
+						From: &gdpv1beta1.ResourceRef{
 							APIVersion: "rds.services.k8s.aws/v1alpha1",
 							Kind:       "DBInstance",
 							Name:       "aws-db-instance",
 						},
-						To: gdpv1alpha1.DestinationRef{
-							Type: gdpv1alpha1.ConfigMap,
+						To: gdpv1beta1.DestinationRef{
+							Type: gdpv1beta1.ConfigMap,
 							Name: "target-cm",
 						},
-						Outputs: []gdpv1alpha1.Output{
+						Outputs: []gdpv1beta1.Output{
 							{
 								Key:  "db-endpoint",
 								Path: ".status.endpoint.address",
@@ -320,27 +1061,27 @@ var _ = Describe("ResourceFieldExport controller", func() {
 				}
 				Expect(k8sClient.Status().Update(ctx, awsDbInstance)).Should(Succeed())
 
-				rfe := &gdpv1alpha1.ResourceFieldExport{
+				rfe := &gdpv1beta1.ResourceFieldExport{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-aws-conditions-ok",
 						Namespace: testNamespace,
 					},
-					Spec: gdpv1alpha1.ResourceFieldExportSpec{
-						From: gdpv1alpha1.ResourceRef{
+					Spec: gdpv1beta1.ResourceFieldExportSpec{
+						From: &gdpv1beta1.ResourceRef{
 							APIVersion: "rds.services.k8s.aws/v1alpha1",
 							Kind:       "DBInstance",
 							Name:       "aws-db-instance",
 						},
-						To: gdpv1alpha1.DestinationRef{
-							Type: gdpv1alpha1.ConfigMap,
+						To: gdpv1beta1.DestinationRef{
+							Type: gdpv1beta1.ConfigMap,
 							Name: "target-cm",
 						},
-						RequiredFields: &gdpv1alpha1.RequiredFields{
-							StatusConditions: []gdpv1alpha1.StatusCondition{
+						RequiredFields: &gdpv1beta1.RequiredFields{
+							StatusConditions: []gdpv1beta1.StatusCondition{
 								{Type: "Ready", Status: "True"},
 							},
 						},
-						Outputs: []gdpv1alpha1.Output{
+						Outputs: []gdpv1beta1.Output{
 							{
 								Key:  "db-endpoint",
 								Path: ".status.endpoint.address",
@@ -359,28 +1100,28 @@ var _ = Describe("ResourceFieldExport controller", func() {
 
 			It("should fail when conditions are not met", func() {
 				ctx := context.Background()
-				rfe := &gdpv1alpha1.ResourceFieldExport{
+				rfe := &gdpv1beta1.ResourceFieldExport{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-aws-conditions-fail",
 						Namespace: testNamespace,
 					},
-					Spec: gdpv1alpha1.ResourceFieldExportSpec{
-						From: gdpv1alpha1.ResourceRef{
+					Spec: gdpv1beta1.ResourceFieldExportSpec{
+						From: &gdpv1beta1.ResourceRef{
 							APIVersion: "rds.services.k8s.aws/v1alpha1",
 							Kind:       "DBInstance",
 							Name:       "aws-db-instance",
 						},
-						To: gdpv1alpha1.DestinationRef{
-							Type: gdpv1alpha1.ConfigMap,
+						To: gdpv1beta1.DestinationRef{
+							Type: gdpv1beta1.ConfigMap,
 							Name: "target-cm",
 						},
-						RequiredFields: &gdpv1alpha1.RequiredFields{
-							StatusConditions: []gdpv1alpha1.StatusCondition{
+						RequiredFields: &gdpv1beta1.RequiredFields{
+							StatusConditions: []gdpv1beta1.StatusCondition{
 								// This condition does not exist on the object
 								{Type: "Available", Status: "True"},
 							},
 						},
-						Outputs: []gdpv1alpha1.Output{
+						Outputs: []gdpv1beta1.Output{
 							// Add a dummy output to satisfy schema validation
 							{Key: "dummy", Path: ".status.dummy"},
 						},
@@ -389,14 +1130,14 @@ var _ = Describe("ResourceFieldExport controller", func() {
 				Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
 
 				// Check that the RFE status becomes degraded
-				Eventually(func() corev1.ConditionStatus {
-					updatedRfe := &gdpv1alpha1.ResourceFieldExport{}
+				Eventually(func() metav1.ConditionStatus {
+					updatedRfe := &gdpv1beta1.ResourceFieldExport{}
 					_ = k8sClient.Get(context.Background(), cr.ObjectKeyFromObject(rfe), updatedRfe)
 					if len(updatedRfe.Status.Conditions) > 0 {
 						return updatedRfe.Status.Conditions[0].Status
 					}
-					return corev1.ConditionUnknown
-				}, "10s").Should(Equal(corev1.ConditionFalse))
+					return metav1.ConditionUnknown
+				}, "10s").Should(Equal(metav1.ConditionFalse))
 			})
 		})
 	})
@@ -446,22 +1187,22 @@ var _ = Describe("ResourceFieldExport controller", func() {
 		When("creating a field export for an AWS DynamoDB resource", func() {
 			It("should succeed and populate the target", func() {
 				ctx := context.Background()
-				rfe := &gdpv1alpha1.ResourceFieldExport{
+				rfe := &gdpv1beta1.ResourceFieldExport{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-aws-dynamodb",
 						Namespace: testNamespace,
 					},
-					Spec: gdpv1alpha1.ResourceFieldExportSpec{
-						From: gdpv1alpha1.ResourceRef{
+					Spec: gdpv1beta1.ResourceFieldExportSpec{
+						From: &gdpv1beta1.ResourceRef{
 							APIVersion: "dynamodb.services.k8s.aws/v1alpha1",
 							Kind:       "Table",
 							Name:       "aws-dynamodb-table",
 						},
-						To: gdpv1alpha1.DestinationRef{
-							Type: gdpv1alpha1.ConfigMap,
+						To: gdpv1beta1.DestinationRef{
+							Type: gdpv1beta1.ConfigMap,
 							Name: "target-cm",
 						},
-						Outputs: []gdpv1alpha1.Output{
+						Outputs: []gdpv1beta1.Output{
 							{
 								Key:  "table-arn",
 								Path: ".status.ackResourceMetadata.arn",
@@ -529,22 +1270,22 @@ var _ = Describe("ResourceFieldExport controller", func() {
 		When("creating a field export for an AWS ElastiCache resource", func() {
 			It("should succeed and populate the target", func() {
 				ctx := context.Background()
-				rfe := &gdpv1alpha1.ResourceFieldExport{
+				rfe := &gdpv1beta1.ResourceFieldExport{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-aws-elasticache",
 						Namespace: testNamespace,
 					},
-					Spec: gdpv1alpha1.ResourceFieldExportSpec{
-						From: gdpv1alpha1.ResourceRef{
+					Spec: gdpv1beta1.ResourceFieldExportSpec{
+						From: &gdpv1beta1.ResourceRef{
 							APIVersion: "elasticache.services.k8s.aws/v1alpha1",
 							Kind:       "ReplicationGroup",
 							Name:       "aws-elasticache-rg",
 						},
-						To: gdpv1alpha1.DestinationRef{
-							Type: gdpv1alpha1.ConfigMap,
+						To: gdpv1beta1.DestinationRef{
+							Type: gdpv1beta1.ConfigMap,
 							Name: "target-cm",
 						},
-						Outputs: []gdpv1alpha1.Output{
+						Outputs: []gdpv1beta1.Output{
 							{
 								Key:  "redis-endpoint",
 								Path: ".status.nodeGroups[0].primaryEndpoint.address",
@@ -564,4 +1305,532 @@ var _ = Describe("ResourceFieldExport controller", func() {
 			})
 		})
 	})
+
+	Context("for existing source resource (Azure Service Operator)", func() {
+		var flexibleServer *unstructured.Unstructured
+
+		BeforeEach(func() {
+			ctx := context.Background()
+			flexibleServer = &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "dbforpostgresql.azure.com/v1api20221201",
+					"kind":       "FlexibleServer",
+					"metadata": map[string]interface{}{
+						"name":      "azure-postgres",
+						"namespace": testNamespace,
+					},
+					"spec": map[string]interface{}{
+						"location": "westeurope",
+						"owner": map[string]interface{}{
+							"name": "resource-group",
+						},
+						"version": "14",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, flexibleServer)).Should(Succeed())
+
+			flexibleServer.Object["status"] = map[string]interface{}{
+				"fullyQualifiedDomainName": "azure-postgres.postgres.database.azure.com",
+				"state":                    "Ready",
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"type":               "Ready",
+						"status":             "True",
+						"severity":           "",
+						"reason":             "Succeeded",
+						"lastTransitionTime": "2024-03-01T10:00:00Z",
+					},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, flexibleServer)).Should(Succeed())
+		})
+
+		When("creating a field export for an Azure PostgreSQL flexible server", func() {
+			It("should succeed and populate the target", func() {
+				ctx := context.Background()
+				rfe := &gdpv1beta1.ResourceFieldExport{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-azure-postgres",
+						Namespace: testNamespace,
+					},
+					Spec: gdpv1beta1.ResourceFieldExportSpec{
+						From: &gdpv1beta1.ResourceRef{
+							APIVersion: "dbforpostgresql.azure.com/v1api20221201",
+							Kind:       "FlexibleServer",
+							Name:       "azure-postgres",
+						},
+						To: gdpv1beta1.DestinationRef{
+							Type: gdpv1beta1.ConfigMap,
+							Name: "target-cm",
+						},
+						RequiredFields: &gdpv1beta1.RequiredFields{
+							StatusConditions: []gdpv1beta1.StatusCondition{
+								{
+									Type:   "Ready",
+									Status: "True",
+								},
+							},
+						},
+						Outputs: []gdpv1beta1.Output{
+							{
+								Key:  "db-host",
+								Path: ".status.fullyQualifiedDomainName",
+							},
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+
+				Eventually(func() string {
+					ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second)
+					defer cancelFunc()
+					cm := &corev1.ConfigMap{}
+					Expect(k8sClient.Get(ctx, cr.ObjectKey{Namespace: testNamespace, Name: "target-cm"}, cm)).Should(Succeed())
+					return cm.Data["db-host"]
+				}, "10s").Should(Equal("azure-postgres.postgres.database.azure.com"))
+			})
+		})
+	})
+
+	Context("for existing source resource (Crossplane)", func() {
+		var bucket *unstructured.Unstructured
+		var grant *gdpv1beta1.FieldExportGrant
+
+		BeforeEach(func() {
+			ctx := context.Background()
+			// managed resources are cluster scoped, the name is unique per test namespace
+			bucket = &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "s3.aws.upbound.io/v1beta1",
+					"kind":       "Bucket",
+					"metadata": map[string]interface{}{
+						"name": "bucket-" + testNamespace,
+					},
+					"spec": map[string]interface{}{
+						"forProvider": map[string]interface{}{
+							"region": "eu-west-1",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, bucket)).Should(Succeed())
+			bucket.Object["status"] = map[string]interface{}{
+				"atProvider": map[string]interface{}{
+					"arn":                      "arn:aws:s3:::" + bucket.GetName(),
+					"bucketRegionalDomainName": bucket.GetName() + ".s3.eu-west-1.amazonaws.com",
+				},
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Ready", "status": "True", "reason": "Available", "lastTransitionTime": "2024-03-01T10:00:00Z"},
+					map[string]interface{}{"type": "Synced", "status": "False", "reason": "ReconcileError", "lastTransitionTime": "2024-03-01T10:00:00Z"},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, bucket)).Should(Succeed())
+
+			// managed resources belong to no namespace, the cluster grant namespace grants them
+			grant = &gdpv1beta1.FieldExportGrant{
+				ObjectMeta: metav1.ObjectMeta{Name: "bucket-" + testNamespace, Namespace: clusterGrantNamespace},
+				Spec: gdpv1beta1.FieldExportGrantSpec{
+					From: []gdpv1beta1.GrantFrom{{Namespace: testNamespace}},
+					To:   []gdpv1beta1.GrantTo{{Group: "s3.aws.upbound.io", Kind: "Bucket", Name: bucket.GetName()}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, grant)).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(context.Background(), bucket)).Should(Succeed())
+			Expect(cr.IgnoreNotFound(k8sClient.Delete(context.Background(), grant))).Should(Succeed())
+		})
+
+		It("should wait for a grant of the cluster grant namespace", func() {
+			ctx := context.Background()
+			Expect(k8sClient.Delete(ctx, grant)).Should(Succeed())
+			rfe := &gdpv1beta1.ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-crossplane-grant",
+					Namespace: testNamespace,
+				},
+				Spec: gdpv1beta1.ResourceFieldExportSpec{
+					From: &gdpv1beta1.ResourceRef{
+						APIVersion: "s3.aws.upbound.io/v1beta1",
+						Kind:       "Bucket",
+						Name:       bucket.GetName(),
+					},
+					To: gdpv1beta1.DestinationRef{
+						Type: gdpv1beta1.ConfigMap,
+						Name: "target-cm",
+					},
+					RequiredFields: &gdpv1beta1.RequiredFields{},
+					Outputs: []gdpv1beta1.Output{
+						{
+							Key:  "bucket-arn",
+							Path: ".status.atProvider.arn",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+
+			readyCondition := func() *metav1.Condition {
+				_ = k8sClient.Get(ctx, cr.ObjectKeyFromObject(rfe), rfe)
+				return apimeta.FindStatusCondition(rfe.Status.Conditions, gdpv1beta1.ReadyCondition)
+			}
+			Eventually(readyCondition, "10s").Should(And(
+				HaveField("Reason", gdpv1beta1.ReasonReferenceNotPermitted),
+				HaveField("Message", ContainSubstring("no FieldExportGrant in namespace "+clusterGrantNamespace)),
+			))
+
+			// the grant enqueues the exports of the namespaces it allows
+			grant.ResourceVersion = ""
+			Expect(k8sClient.Create(ctx, grant)).Should(Succeed())
+			Eventually(func() string {
+				cm := &corev1.ConfigMap{}
+				_ = k8sClient.Get(ctx, cr.ObjectKey{Namespace: testNamespace, Name: "target-cm"}, cm)
+				return cm.Data["bucket-arn"]
+			}, "10s").Should(Equal("arn:aws:s3:::" + bucket.GetName()))
+		})
+
+		It("should wait for Ready and Synced by default", func() {
+			ctx := context.Background()
+			rfe := &gdpv1beta1.ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-crossplane",
+					Namespace: testNamespace,
+				},
+				Spec: gdpv1beta1.ResourceFieldExportSpec{
+					From: &gdpv1beta1.ResourceRef{
+						APIVersion: "s3.aws.upbound.io/v1beta1",
+						Kind:       "Bucket",
+						Name:       bucket.GetName(),
+					},
+					To: gdpv1beta1.DestinationRef{
+						Type: gdpv1beta1.ConfigMap,
+						Name: "target-cm",
+					},
+					Outputs: []gdpv1beta1.Output{
+						{
+							Key:  "bucket-arn",
+							Path: ".status.atProvider.arn",
+						},
+						{
+							Key:  "bucket-domain",
+							Path: ".status.atProvider.bucketRegionalDomainName",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+
+			Eventually(func() []metav1.Condition {
+				_ = k8sClient.Get(ctx, cr.ObjectKeyFromObject(rfe), rfe)
+				return rfe.Status.Conditions
+			}, "10s").Should(ConsistOf(And(
+				HaveField("Type", gdpv1beta1.ReadyCondition),
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", gdpv1beta1.ReasonRequiredConditionsNotMet),
+				HaveField("Message", "status condition Synced has value False, expected True"),
+			)))
+
+			// the source is synced, the export follows through the watch
+			bucket.Object["status"].(map[string]interface{})["conditions"].([]interface{})[1] = map[string]interface{}{
+				"type": "Synced", "status": "True", "reason": "ReconcileSuccess", "lastTransitionTime": "2024-03-01T10:05:00Z",
+			}
+			Expect(k8sClient.Status().Update(ctx, bucket)).Should(Succeed())
+			Eventually(func() map[string]string {
+				cm := &corev1.ConfigMap{}
+				_ = k8sClient.Get(ctx, cr.ObjectKey{Namespace: testNamespace, Name: "target-cm"}, cm)
+				return cm.Data
+			}, "10s").Should(And(
+				HaveKeyWithValue("bucket-arn", "arn:aws:s3:::"+bucket.GetName()),
+				HaveKeyWithValue("bucket-domain", bucket.GetName()+".s3.eu-west-1.amazonaws.com"),
+			))
+		})
+
+		It("should not wait for the conditions when requiredFields is set", func() {
+			ctx := context.Background()
+			rfe := &gdpv1beta1.ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-crossplane-no-conditions",
+					Namespace: testNamespace,
+				},
+				Spec: gdpv1beta1.ResourceFieldExportSpec{
+					From: &gdpv1beta1.ResourceRef{
+						APIVersion: "s3.aws.upbound.io/v1beta1",
+						Kind:       "Bucket",
+						Name:       bucket.GetName(),
+					},
+					To: gdpv1beta1.DestinationRef{
+						Type: gdpv1beta1.ConfigMap,
+						Name: "target-cm",
+					},
+					RequiredFields: &gdpv1beta1.RequiredFields{},
+					Outputs: []gdpv1beta1.Output{
+						{
+							Key:  "bucket-arn",
+							Path: ".status.atProvider.arn",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+
+			Eventually(func() string {
+				cm := &corev1.ConfigMap{}
+				_ = k8sClient.Get(ctx, cr.ObjectKey{Namespace: testNamespace, Name: "target-cm"}, cm)
+				return cm.Data["bucket-arn"]
+			}, "10s").Should(Equal("arn:aws:s3:::" + bucket.GetName()))
+		})
+	})
+
+	Context("for a source kind installed after the start", func() {
+		It("should watch the kind once it is discovered", func() {
+			ctx := context.Background()
+			// the kind of a Crossplane provider installed after field-exporter
+			queueCRD := &apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "queues.sqs.aws.upbound.io"},
+				Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+					Group: "sqs.aws.upbound.io",
+					Names: apiextensionsv1.CustomResourceDefinitionNames{
+						Plural:   "queues",
+						Singular: "queue",
+						Kind:     "Queue",
+						ListKind: "QueueList",
+					},
+					Scope: apiextensionsv1.ClusterScoped,
+					Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+						Name:    "v1beta1",
+						Served:  true,
+						Storage: true,
+						Schema: &apiextensionsv1.CustomResourceValidation{
+							OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+								Type:                   "object",
+								XPreserveUnknownFields: ptr.To(true),
+							},
+						},
+						Subresources: &apiextensionsv1.CustomResourceSubresources{
+							Status: &apiextensionsv1.CustomResourceSubresourceStatus{},
+						},
+					}},
+				},
+			}
+			_, err := envtest.InstallCRDs(cfg, envtest.CRDInstallOptions{
+				CRDs: []*apiextensionsv1.CustomResourceDefinition{queueCRD},
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			DeferCleanup(func() {
+				Expect(envtest.UninstallCRDs(cfg, envtest.CRDInstallOptions{
+					CRDs: []*apiextensionsv1.CustomResourceDefinition{queueCRD},
+				})).Should(Succeed())
+			})
+
+			queue := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "sqs.aws.upbound.io/v1beta1",
+					"kind":       "Queue",
+					"metadata": map[string]interface{}{
+						"name": "queue-" + testNamespace,
+					},
+					"spec": map[string]interface{}{
+						"forProvider": map[string]interface{}{
+							"region": "eu-west-1",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, queue)).Should(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(context.Background(), queue)).Should(Succeed())
+			})
+			queue.Object["status"] = map[string]interface{}{
+				"atProvider": map[string]interface{}{
+					"url": "https://sqs.eu-west-1.amazonaws.com/123456789012/orders",
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, queue)).Should(Succeed())
+			Expect(k8sClient.Create(ctx, &gdpv1beta1.FieldExportGrant{
+				ObjectMeta: metav1.ObjectMeta{Name: "queue-" + testNamespace, Namespace: clusterGrantNamespace},
+				Spec: gdpv1beta1.FieldExportGrantSpec{
+					From: []gdpv1beta1.GrantFrom{{Namespace: testNamespace}},
+					To:   []gdpv1beta1.GrantTo{{Group: "sqs.aws.upbound.io", Kind: "Queue"}},
+				},
+			})).Should(Succeed())
+
+			rfe := &gdpv1beta1.ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-late-kind",
+					Namespace: testNamespace,
+				},
+				Spec: gdpv1beta1.ResourceFieldExportSpec{
+					From: &gdpv1beta1.ResourceRef{
+						APIVersion: "sqs.aws.upbound.io/v1beta1",
+						Kind:       "Queue",
+						Name:       queue.GetName(),
+					},
+					To: gdpv1beta1.DestinationRef{
+						Type: gdpv1beta1.ConfigMap,
+						Name: "target-cm",
+					},
+					// the test objects have no conditions
+					RequiredFields: &gdpv1beta1.RequiredFields{},
+					Outputs: []gdpv1beta1.Output{
+						{
+							Key:  "queue-url",
+							Path: ".status.atProvider.url",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+
+			queueURL := func() string {
+				cm := &corev1.ConfigMap{}
+				_ = k8sClient.Get(ctx, cr.ObjectKey{Namespace: testNamespace, Name: "target-cm"}, cm)
+				return cm.Data["queue-url"]
+			}
+			Eventually(queueURL, "10s").Should(Equal("https://sqs.eu-west-1.amazonaws.com/123456789012/orders"))
+
+			// changes of the source trigger a reconcile through the watch started after the discovery
+			queue.Object["status"].(map[string]interface{})["atProvider"] = map[string]interface{}{
+				"url": "https://sqs.eu-west-1.amazonaws.com/123456789012/orders-v2",
+			}
+			Expect(k8sClient.Status().Update(ctx, queue)).Should(Succeed())
+			Eventually(queueURL, "10s").Should(Equal("https://sqs.eu-west-1.amazonaws.com/123456789012/orders-v2"))
+		})
+	})
+
+	Context("for a source and a destination in other namespaces", func() {
+		var platformNamespace, sharedNamespace string
+
+		BeforeEach(func() {
+			ctx := context.Background()
+			platformNamespace, sharedNamespace = testNamespace+"-platform", testNamespace+"-shared"
+			for _, name := range []string{platformNamespace, sharedNamespace} {
+				Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}})).Should(Succeed())
+			}
+
+			platformRedis := redisInstance.DeepCopy()
+			platformRedis.ObjectMeta = metav1.ObjectMeta{Name: "platform-redis", Namespace: platformNamespace}
+			platformRedis.Spec.DisplayName = ptr.To("platform-redis")
+			platformRedisMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(platformRedis)
+			Expect(err).Should(Succeed())
+			Expect(k8sClient.Create(ctx, &unstructured.Unstructured{Object: platformRedisMap})).Should(Succeed())
+
+			Expect(k8sClient.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "shared-cm", Namespace: sharedNamespace},
+			})).Should(Succeed())
+		})
+
+		It("should export only what FieldExportGrants of both namespaces allow", func() {
+			ctx := context.Background()
+			rfe := &gdpv1beta1.ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cross-namespace",
+					Namespace: testNamespace,
+				},
+				Spec: gdpv1beta1.ResourceFieldExportSpec{
+					From: &gdpv1beta1.ResourceRef{
+						APIVersion: "redis.cnrm.cloud.google.com/v1beta1",
+						Kind:       "RedisInstance",
+						Name:       "platform-redis",
+						Namespace:  platformNamespace,
+					},
+					To: gdpv1beta1.DestinationRef{
+						Type:      gdpv1beta1.ConfigMap,
+						Name:      "shared-cm",
+						Namespace: sharedNamespace,
+					},
+					RequiredFields: &gdpv1beta1.RequiredFields{},
+					Outputs: []gdpv1beta1.Output{
+						{
+							Key:  "display-name",
+							Path: ".spec.displayName",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, rfe)).Should(Succeed())
+
+			readyCondition := func() *metav1.Condition {
+				_ = k8sClient.Get(ctx, cr.ObjectKeyFromObject(rfe), rfe)
+				return apimeta.FindStatusCondition(rfe.Status.Conditions, gdpv1beta1.ReadyCondition)
+			}
+			Eventually(readyCondition, "10s").Should(And(
+				HaveField("Reason", gdpv1beta1.ReasonReferenceNotPermitted),
+				HaveField("Message", ContainSubstring("no FieldExportGrant in namespace "+platformNamespace)),
+			))
+
+			// the grant of the source namespace alone doesn't allow writing to the shared namespace
+			grant := func(namespace string, to gdpv1beta1.GrantTo) *gdpv1beta1.FieldExportGrant {
+				return &gdpv1beta1.FieldExportGrant{
+					ObjectMeta: metav1.ObjectMeta{Name: "grant", Namespace: namespace},
+					Spec: gdpv1beta1.FieldExportGrantSpec{
+						From: []gdpv1beta1.GrantFrom{{Namespace: testNamespace}},
+						To:   []gdpv1beta1.GrantTo{to},
+					},
+				}
+			}
+			platformGrant := grant(platformNamespace, gdpv1beta1.GrantTo{
+				Group: "redis.cnrm.cloud.google.com",
+				Kind:  "RedisInstance",
+			})
+			Expect(k8sClient.Create(ctx, platformGrant)).Should(Succeed())
+			Eventually(readyCondition, "10s").Should(And(
+				HaveField("Reason", gdpv1beta1.ReasonReferenceNotPermitted),
+				HaveField("Message", ContainSubstring("no FieldExportGrant in namespace "+sharedNamespace)),
+			))
+
+			sharedGrant := grant(sharedNamespace, gdpv1beta1.GrantTo{Kind: "ConfigMap", Name: "shared-cm"})
+			Expect(k8sClient.Create(ctx, sharedGrant)).Should(Succeed())
+			cm := &corev1.ConfigMap{}
+			Eventually(func() string {
+				_ = k8sClient.Get(ctx, cr.ObjectKey{Namespace: sharedNamespace, Name: "shared-cm"}, cm)
+				return cm.Data["display-name"]
+			}, "10s").Should(Equal("platform-redis"))
+			Expect(cm.ManagedFields).Should(ContainElement(
+				HaveField("Manager", "field-exporter/"+testNamespace+"/cross-namespace"),
+			))
+			Eventually(readyCondition, "10s").Should(HaveField("Reason", gdpv1beta1.ReasonSynced))
+
+			// removing the namespace of the export from a grant stops the export
+			platformGrant.Spec.From = []gdpv1beta1.GrantFrom{{Namespace: sharedNamespace}}
+			Expect(k8sClient.Update(ctx, platformGrant)).Should(Succeed())
+			Eventually(readyCondition, "10s").Should(And(
+				HaveField("Reason", gdpv1beta1.ReasonReferenceNotPermitted),
+				HaveField("Message", ContainSubstring("no FieldExportGrant in namespace "+platformNamespace)),
+			))
+			platformGrant.Spec.From = []gdpv1beta1.GrantFrom{{Namespace: testNamespace}}
+			Expect(k8sClient.Update(ctx, platformGrant)).Should(Succeed())
+			Eventually(readyCondition, "10s").Should(HaveField("Reason", gdpv1beta1.ReasonSynced))
+
+			// revoking a grant stops the export
+			Expect(k8sClient.Delete(ctx, sharedGrant)).Should(Succeed())
+			Eventually(readyCondition, "10s").Should(HaveField("Reason", gdpv1beta1.ReasonReferenceNotPermitted))
+		})
+
+		It("should reject creating a destination in another namespace", func() {
+			rfe := &gdpv1beta1.ResourceFieldExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cross-namespace-create",
+					Namespace: testNamespace,
+				},
+				Spec: gdpv1beta1.ResourceFieldExportSpec{
+					From: &gdpv1beta1.ResourceRef{
+						APIVersion: "redis.cnrm.cloud.google.com/v1beta1",
+						Kind:       "RedisInstance",
+						Name:       "redis-instance",
+					},
+					To: gdpv1beta1.DestinationRef{
+						Type:         gdpv1beta1.ConfigMap,
+						Name:         "new-cm",
+						Namespace:    sharedNamespace,
+						CreatePolicy: gdpv1beta1.CreateIfMissing,
+					},
+					Outputs: []gdpv1beta1.Output{{Key: "display-name", Path: ".spec.displayName"}},
+				},
+			}
+			Expect(k8sClient.Create(context.Background(), rfe)).Should(MatchError(
+				ContainSubstring("createPolicy CreateIfMissing can't be combined with a namespace")))
+		})
+	})
 })
@@ -0,0 +1,101 @@
+package resourcefieldexport
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
+	"github.com/deliveryhero/field-exporter/internal/resourcemanager"
+)
+
+// Evaluation is the outcome of evaluating an export without a cluster
+type Evaluation struct {
+	// Destination is the ConfigMap or Secret holding only the keys the export would apply
+	Destination client.Object
+	// Fallbacks are the outputs whose path resolved to null
+	Fallbacks []gdpv1beta1.OutputFallback
+	// Stale are the keys in status.exportedKeys of the export that it would remove, when they were
+	// written to the destination `to` refers to
+	Stale []string
+}
+
+// ClusterScope tells an evaluation which sources are cluster scoped, without a cluster their
+// scope is only known by their group
+type ClusterScope struct {
+	// Groups are the API groups of the cluster scoped source kinds, e.g. CrossplaneGroups
+	Groups resourcemanager.Groups
+	// GrantNamespace is the namespace whose FieldExportGrants allow reading from them
+	GrantNamespace string
+}
+
+// Evaluate runs the outputs of an export against source objects read from anywhere, e.g. files,
+// the same way the reconciler does against the cluster. Sources are matched by apiVersion, kind
+// and name, and by namespace when both the source and the export set one, their kind must be
+// allowed by the allowlist. Sources and destinations in other namespaces, and cluster scoped
+// sources, must be allowed by one of the grants.
+func Evaluate(ctx context.Context, export *gdpv1beta1.ResourceFieldExport, objects []*unstructured.Unstructured, allowlist resourcemanager.SourceAllowlist, grants []gdpv1beta1.FieldExportGrant, clusterScope ClusterScope) (*Evaluation, error) {
+	input := make(map[string]any)
+	for _, source := range exportSources(export.Spec) {
+		group, _, err := groupVersion(source.ResourceRef, allowlist)
+		if err != nil {
+			return nil, err
+		}
+		gk := schema.GroupKind{Group: group, Kind: source.Kind}
+		grantedBy, err := grantNamespace(export, source.ResourceRef, gk, !clusterScope.Groups.Allows(group), clusterScope.GrantNamespace)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkGrant(grants, export, grantedBy, gk, source.Name); err != nil {
+			return nil, err
+		}
+		namespace := sourceNamespace(export, source.ResourceRef)
+		object := findSource(objects, source.ResourceRef, namespace)
+		if object == nil {
+			return nil, fmt.Errorf("source %s %s not found", source.Kind, source.Name)
+		}
+		if conditions := requiredConditions(export.Spec, group); len(conditions) > 0 {
+			if err := verifyStatusConditions(ctx, object.Object, conditions); err != nil {
+				if source.Alias != "" {
+					err = fmt.Errorf("source %s: %w", source.Alias, err)
+				}
+				return nil, err
+			}
+		}
+		if source.Alias == "" {
+			input = object.Object
+		} else {
+			input[source.Alias] = object.Object
+		}
+	}
+
+	values, fallbacks, _, err := resolveOutputs(ctx, input, export.Spec.Outputs)
+	if err != nil {
+		return nil, err
+	}
+	destination := schema.GroupKind{Kind: string(export.Spec.To.Type)}
+	if err := checkGrant(grants, export, destinationNamespace(export), destination, export.Spec.To.Name); err != nil {
+		return nil, err
+	}
+	return &Evaluation{
+		Destination: applyConfiguration(export, values),
+		Fallbacks:   fallbacks,
+		Stale:       staleKeys(writtenKeys(export), values),
+	}, nil
+}
+
+func findSource(objects []*unstructured.Unstructured, ref gdpv1beta1.ResourceRef, namespace string) *unstructured.Unstructured {
+	for _, object := range objects {
+		if object.GetAPIVersion() != ref.APIVersion || object.GetKind() != ref.Kind || object.GetName() != ref.Name {
+			continue
+		}
+		if namespace != "" && object.GetNamespace() != "" && object.GetNamespace() != namespace {
+			continue
+		}
+		return object
+	}
+	return nil
+}
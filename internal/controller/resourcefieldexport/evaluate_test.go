@@ -0,0 +1,242 @@
+package resourcefieldexport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
+	"github.com/deliveryhero/field-exporter/internal/resourcemanager"
+)
+
+func TestEvaluate(t *testing.T) {
+	redis := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "redis.cnrm.cloud.google.com/v1beta1",
+		"kind":       "RedisInstance",
+		"metadata":   map[string]any{"name": "cache", "namespace": "app"},
+		"status": map[string]any{
+			"host":       "10.0.0.3",
+			"port":       int64(6379),
+			"conditions": []any{map[string]any{"type": "Ready", "status": "True"}},
+		},
+	}}
+	other := redis.DeepCopy()
+	other.SetNamespace("other")
+	other.Object["status"].(map[string]any)["host"] = "10.0.0.9"
+	redisRef := gdpv1beta1.ResourceRef{APIVersion: "redis.cnrm.cloud.google.com/v1beta1", Kind: "RedisInstance", Name: "cache"}
+	ready := &gdpv1beta1.RequiredFields{StatusConditions: []gdpv1beta1.StatusCondition{{Type: "Ready", Status: "True"}}}
+	otherRef := redisRef
+	otherRef.Namespace = "other"
+	grant := func(namespace, from string, to gdpv1beta1.GrantTo) gdpv1beta1.FieldExportGrant {
+		g := gdpv1beta1.FieldExportGrant{Spec: gdpv1beta1.FieldExportGrantSpec{
+			From: []gdpv1beta1.GrantFrom{{Namespace: from}},
+			To:   []gdpv1beta1.GrantTo{to},
+		}}
+		g.Name, g.Namespace = "grant", namespace
+		return g
+	}
+	redisGrant := gdpv1beta1.GrantTo{Group: "redis.cnrm.cloud.google.com", Kind: "RedisInstance"}
+	bucket := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "s3.aws.upbound.io/v1beta1",
+		"kind":       "Bucket",
+		"metadata":   map[string]any{"name": "assets"},
+		"status": map[string]any{
+			"atProvider": map[string]any{"arn": "arn:aws:s3:::assets"},
+			"conditions": []any{
+				map[string]any{"type": "Ready", "status": "True"},
+				map[string]any{"type": "Synced", "status": "True"},
+			},
+		},
+	}}
+	bucketRef := gdpv1beta1.ResourceRef{APIVersion: "s3.aws.upbound.io/v1beta1", Kind: "Bucket", Name: "assets"}
+	bucketGrant := gdpv1beta1.GrantTo{Group: "s3.aws.upbound.io", Kind: "Bucket", Name: "assets"}
+	clusterScope := ClusterScope{Groups: resourcemanager.CrossplaneGroups, GrantNamespace: "field-exporter-system"}
+
+	for _, tc := range []struct {
+		name              string
+		spec              gdpv1beta1.ResourceFieldExportSpec
+		objects           []*unstructured.Unstructured
+		grants            []gdpv1beta1.FieldExportGrant
+		clusterScope      *ClusterScope
+		to                *gdpv1beta1.DestinationRef
+		expectedNamespace string
+		expectedData      map[string]string
+		expectedFallbacks []gdpv1beta1.OutputFallback
+		expectErr         string
+	}{
+		{
+			name: "from",
+			spec: gdpv1beta1.ResourceFieldExportSpec{
+				From:           &redisRef,
+				RequiredFields: ready,
+				Outputs: []gdpv1beta1.Output{
+					{Key: "host", Path: ".status.host"},
+					{Key: "port", Path: ".status.port"},
+					{Key: "read-port", Path: ".status.readEndpointPort", Optional: true},
+				},
+			},
+			objects:           []*unstructured.Unstructured{other, redis},
+			expectedData:      map[string]string{"host": "10.0.0.3", "port": "6379"},
+			expectedFallbacks: []gdpv1beta1.OutputFallback{{Key: "read-port", Policy: gdpv1beta1.NullSkip}},
+		},
+		{
+			name: "sources",
+			spec: gdpv1beta1.ResourceFieldExportSpec{
+				Sources: []gdpv1beta1.Source{{Alias: "cache", ResourceRef: redisRef}},
+				Outputs: []gdpv1beta1.Output{{Key: "url", Template: "redis://{{ .cache.status.host }}:{{ .cache.status.port }}"}},
+			},
+			objects:      []*unstructured.Unstructured{redis},
+			expectedData: map[string]string{"url": "redis://10.0.0.3:6379"},
+		},
+		{
+			name: "source in another namespace",
+			spec: gdpv1beta1.ResourceFieldExportSpec{
+				From:    &otherRef,
+				Outputs: []gdpv1beta1.Output{{Key: "host", Path: ".status.host"}},
+			},
+			objects:      []*unstructured.Unstructured{redis, other},
+			grants:       []gdpv1beta1.FieldExportGrant{grant("other", "app", redisGrant)},
+			expectedData: map[string]string{"host": "10.0.0.9"},
+		},
+		{
+			name: "source in another namespace without grant",
+			spec: gdpv1beta1.ResourceFieldExportSpec{
+				From:    &otherRef,
+				Outputs: []gdpv1beta1.Output{{Key: "host", Path: ".status.host"}},
+			},
+			objects: []*unstructured.Unstructured{redis, other},
+			// grants of the namespace of the export or for other namespaces don't count
+			grants: []gdpv1beta1.FieldExportGrant{
+				grant("app", "app", redisGrant),
+				grant("other", "payments", redisGrant),
+			},
+			expectErr: "reference not permitted: no FieldExportGrant in namespace other allows exports of namespace app to refer to RedisInstance.redis.cnrm.cloud.google.com cache",
+		},
+		{
+			name: "cluster scoped source",
+			spec: gdpv1beta1.ResourceFieldExportSpec{
+				From:    &bucketRef,
+				Outputs: []gdpv1beta1.Output{{Key: "arn", Path: ".status.atProvider.arn"}},
+			},
+			objects:      []*unstructured.Unstructured{bucket},
+			grants:       []gdpv1beta1.FieldExportGrant{grant("field-exporter-system", "app", bucketGrant)},
+			expectedData: map[string]string{"arn": "arn:aws:s3:::assets"},
+		},
+		{
+			name: "cluster scoped source without grant",
+			spec: gdpv1beta1.ResourceFieldExportSpec{
+				From:    &bucketRef,
+				Outputs: []gdpv1beta1.Output{{Key: "arn", Path: ".status.atProvider.arn"}},
+			},
+			objects: []*unstructured.Unstructured{bucket},
+			// the namespace of the export can't grant a resource of no namespace
+			grants:    []gdpv1beta1.FieldExportGrant{grant("app", "app", bucketGrant)},
+			expectErr: "reference not permitted: no FieldExportGrant in namespace field-exporter-system allows exports of namespace app to refer to Bucket.s3.aws.upbound.io assets",
+		},
+		{
+			name: "cluster scoped source without grant namespace",
+			spec: gdpv1beta1.ResourceFieldExportSpec{
+				From:    &bucketRef,
+				Outputs: []gdpv1beta1.Output{{Key: "arn", Path: ".status.atProvider.arn"}},
+			},
+			objects:      []*unstructured.Unstructured{bucket},
+			grants:       []gdpv1beta1.FieldExportGrant{grant("field-exporter-system", "app", bucketGrant)},
+			clusterScope: &ClusterScope{Groups: resourcemanager.CrossplaneGroups},
+			expectErr:    "reference not permitted: Bucket.s3.aws.upbound.io assets is cluster scoped and no namespace grants cluster scoped sources",
+		},
+		{
+			name: "destination in another namespace",
+			spec: gdpv1beta1.ResourceFieldExportSpec{
+				From:    &redisRef,
+				Outputs: []gdpv1beta1.Output{{Key: "host", Path: ".status.host"}},
+			},
+			objects:           []*unstructured.Unstructured{redis},
+			grants:            []gdpv1beta1.FieldExportGrant{grant("shared", "app", gdpv1beta1.GrantTo{Kind: "ConfigMap", Name: "config"})},
+			to:                &gdpv1beta1.DestinationRef{Type: gdpv1beta1.ConfigMap, Name: "config", Namespace: "shared"},
+			expectedNamespace: "shared",
+			expectedData:      map[string]string{"host": "10.0.0.3"},
+		},
+		{
+			name: "destination in another namespace granted by name",
+			spec: gdpv1beta1.ResourceFieldExportSpec{
+				From:    &redisRef,
+				Outputs: []gdpv1beta1.Output{{Key: "host", Path: ".status.host"}},
+			},
+			objects:   []*unstructured.Unstructured{redis},
+			grants:    []gdpv1beta1.FieldExportGrant{grant("shared", "app", gdpv1beta1.GrantTo{Kind: "ConfigMap", Name: "other-config"})},
+			to:        &gdpv1beta1.DestinationRef{Type: gdpv1beta1.ConfigMap, Name: "config", Namespace: "shared"},
+			expectErr: "no FieldExportGrant in namespace shared allows exports of namespace app to refer to ConfigMap config",
+		},
+		{
+			name: "group not allowed",
+			spec: gdpv1beta1.ResourceFieldExportSpec{
+				From:    &gdpv1beta1.ResourceRef{APIVersion: "compute.cnrm.cloud.google.com/v1beta1", Kind: "ComputeAddress", Name: "ip"},
+				Outputs: []gdpv1beta1.Output{{Key: "ip", Path: ".spec.address"}},
+			},
+			objects:   []*unstructured.Unstructured{redis},
+			expectErr: "unsupported apiVersion: compute.cnrm.cloud.google.com/v1beta1, group must match one of",
+		},
+		{
+			name: "missing source",
+			spec: gdpv1beta1.ResourceFieldExportSpec{
+				From:    &redisRef,
+				Outputs: []gdpv1beta1.Output{{Key: "host", Path: ".status.host"}},
+			},
+			objects:   []*unstructured.Unstructured{other},
+			expectErr: "source RedisInstance cache not found",
+		},
+		{
+			name: "unmet conditions",
+			spec: gdpv1beta1.ResourceFieldExportSpec{
+				Sources:        []gdpv1beta1.Source{{Alias: "cache", ResourceRef: redisRef}},
+				RequiredFields: &gdpv1beta1.RequiredFields{StatusConditions: []gdpv1beta1.StatusCondition{{Type: "Ready", Status: "False"}}},
+				Outputs:        []gdpv1beta1.Output{{Key: "host", Path: ".cache.status.host"}},
+			},
+			objects:   []*unstructured.Unstructured{redis},
+			expectErr: "source cache: ",
+		},
+		{
+			name: "broken paths",
+			spec: gdpv1beta1.ResourceFieldExportSpec{
+				From: &redisRef,
+				Outputs: []gdpv1beta1.Output{
+					{Key: "host", Path: ".status.hostt"},
+					{Key: "port", Path: ".status.prot"},
+				},
+			},
+			objects:   []*unstructured.Unstructured{redis},
+			expectErr: "no results returned for query .status.hostt\nno results returned for query .status.prot",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			export := &gdpv1beta1.ResourceFieldExport{Spec: tc.spec}
+			export.Name, export.Namespace = "export", "app"
+			export.Spec.To = gdpv1beta1.DestinationRef{Type: gdpv1beta1.ConfigMap, Name: "config"}
+			if tc.to != nil {
+				export.Spec.To = *tc.to
+			}
+
+			if tc.clusterScope == nil {
+				tc.clusterScope = &clusterScope
+			}
+			evaluation, err := Evaluate(context.Background(), export, tc.objects, resourcemanager.DefaultGroups, tc.grants, *tc.clusterScope)
+			if tc.expectErr != "" {
+				require.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			cm, ok := evaluation.Destination.(*corev1.ConfigMap)
+			require.True(t, ok)
+			require.Equal(t, "config", cm.Name)
+			if tc.expectedNamespace == "" {
+				tc.expectedNamespace = "app"
+			}
+			require.Equal(t, tc.expectedNamespace, cm.Namespace)
+			require.Equal(t, tc.expectedData, cm.Data)
+			require.Equal(t, tc.expectedFallbacks, evaluation.Fallbacks)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package resourcefieldexport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
+)
+
+// namespaceField indexes exports by the other namespaces they refer to
+const namespaceField = ".spec.namespaces"
+
+// errNotPermitted is returned for a reference to another namespace no FieldExportGrant allows
+var errNotPermitted = errors.New("reference not permitted")
+
+// sourceNamespace returns the namespace a source is read from.
+func sourceNamespace(export *gdpv1beta1.ResourceFieldExport, ref gdpv1beta1.ResourceRef) string {
+	if ref.Namespace != "" {
+		return ref.Namespace
+	}
+	return export.Namespace
+}
+
+// grantNamespace returns the namespace whose grants allow an export to read from a source. A
+// cluster scoped source belongs to no namespace that could consent, it must be granted by a
+// FieldExportGrant in the cluster grant namespace, which only administrators can write to. No
+// cluster scoped source is permitted without one.
+func grantNamespace(export *gdpv1beta1.ResourceFieldExport, ref gdpv1beta1.ResourceRef, gk schema.GroupKind, namespaced bool, clusterGrantNamespace string) (string, error) {
+	if namespaced {
+		return sourceNamespace(export, ref), nil
+	}
+	if clusterGrantNamespace == "" {
+		return "", fmt.Errorf("%w: %s %s is cluster scoped and no namespace grants cluster scoped sources",
+			errNotPermitted, gk, ref.Name)
+	}
+	return clusterGrantNamespace, nil
+}
+
+// destinationNamespace returns the namespace the destination is written to.
+func destinationNamespace(export *gdpv1beta1.ResourceFieldExport) string {
+	if export.Spec.To.Namespace != "" {
+		return export.Spec.To.Namespace
+	}
+	return export.Namespace
+}
+
+// referencedNamespaces returns the namespaces other than its own an export reads from or
+// writes to.
+func referencedNamespaces(export *gdpv1beta1.ResourceFieldExport) []string {
+	var namespaces []string
+	add := func(namespace string) {
+		if namespace != export.Namespace && !slices.Contains(namespaces, namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	for _, source := range exportSources(export.Spec) {
+		add(sourceNamespace(export, source.ResourceRef))
+	}
+	add(destinationNamespace(export))
+	return namespaces
+}
+
+// checkGrant returns errNotPermitted unless the resource is in the namespace of the export or
+// one of the grants of its namespace allows the export to refer to it.
+func checkGrant(grants []gdpv1beta1.FieldExportGrant, export *gdpv1beta1.ResourceFieldExport, namespace string, gk schema.GroupKind, name string) error {
+	if namespace == export.Namespace {
+		return nil
+	}
+	for i := range grants {
+		if grants[i].Namespace == namespace && grants[i].Permits(export.Namespace, gk.Group, gk.Kind, name) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no FieldExportGrant in namespace %s allows exports of namespace %s to refer to %s %s",
+		errNotPermitted, namespace, export.Namespace, gk, name)
+}
+
+// grantsOf returns the grants of a namespace, no grant is needed for the namespace of the export.
+func (r *Reconciler) grantsOf(ctx context.Context, export *gdpv1beta1.ResourceFieldExport, namespace string) ([]gdpv1beta1.FieldExportGrant, error) {
+	if namespace == export.Namespace {
+		return nil, nil
+	}
+	grants := &gdpv1beta1.FieldExportGrantList{}
+	if err := r.List(ctx, grants, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	return grants.Items, nil
+}
+
+// permitted checks the grants of the namespace of a resource the export refers to.
+func (r *Reconciler) permitted(ctx context.Context, export *gdpv1beta1.ResourceFieldExport, namespace string, gk schema.GroupKind, name string) error {
+	grants, err := r.grantsOf(ctx, export, namespace)
+	if err != nil {
+		return err
+	}
+	return checkGrant(grants, export, namespace, gk, name)
+}
+
+// findGrantedExports returns the exports that refer to the namespace of a grant, they are
+// reconciled when the grant is created, changed or deleted. Every such export is returned, not
+// only those of the namespaces in `from`, as an export of a namespace removed from it has lost
+// the grant. Exports don't refer to the cluster grant namespace, a grant of it returns the exports
+// of the namespaces it allows; on updates the old grant is mapped as well, which returns the
+// exports of the namespaces removed from it.
+func (r *Reconciler) findGrantedExports(ctx context.Context, obj client.Object) []reconcile.Request {
+	grant, ok := obj.(*gdpv1beta1.FieldExportGrant)
+	if !ok {
+		return nil
+	}
+	listOptions := [][]client.ListOption{{client.MatchingFields{namespaceField: grant.Namespace}}}
+	if grant.Namespace == r.ClusterGrantNamespace {
+		listOptions = nil
+		for _, from := range grant.Spec.From {
+			listOptions = append(listOptions, []client.ListOption{client.InNamespace(from.Namespace)})
+		}
+	}
+	var requests []reconcile.Request
+	for _, opts := range listOptions {
+		exportList := &gdpv1beta1.ResourceFieldExportList{}
+		if err := r.List(ctx, exportList, opts...); err != nil {
+			log.FromContext(ctx).Error(err, "failed to list ResourceFieldExports for grant",
+				"grant", client.ObjectKeyFromObject(grant))
+			continue
+		}
+		for _, export := range exportList.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: export.Namespace, Name: export.Name},
+			})
+		}
+	}
+	return requests
+}
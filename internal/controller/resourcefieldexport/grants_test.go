@@ -0,0 +1,146 @@
+package resourcefieldexport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
+)
+
+func TestReferencedNamespaces(t *testing.T) {
+	redisRef := gdpv1beta1.ResourceRef{APIVersion: "redis.cnrm.cloud.google.com/v1beta1", Kind: "RedisInstance", Name: "cache"}
+	sqlRef := gdpv1beta1.ResourceRef{APIVersion: "sql.cnrm.cloud.google.com/v1beta1", Kind: "SQLInstance", Name: "db"}
+	for _, tc := range []struct {
+		name     string
+		spec     gdpv1beta1.ResourceFieldExportSpec
+		expected []string
+	}{
+		{
+			name: "same namespace",
+			spec: gdpv1beta1.ResourceFieldExportSpec{
+				From: &redisRef,
+				To:   gdpv1beta1.DestinationRef{Type: gdpv1beta1.ConfigMap, Name: "config", Namespace: "app"},
+			},
+		},
+		{
+			name: "sources and destination in other namespaces",
+			spec: gdpv1beta1.ResourceFieldExportSpec{
+				Sources: []gdpv1beta1.Source{
+					{Alias: "cache", ResourceRef: withNamespace(redisRef, "platform")},
+					{Alias: "db", ResourceRef: withNamespace(sqlRef, "platform")},
+					{Alias: "local", ResourceRef: sqlRef},
+				},
+				To: gdpv1beta1.DestinationRef{Type: gdpv1beta1.Secret, Name: "config", Namespace: "shared"},
+			},
+			expected: []string{"platform", "shared"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			export := &gdpv1beta1.ResourceFieldExport{ObjectMeta: metav1.ObjectMeta{Name: "export", Namespace: "app"}, Spec: tc.spec}
+			require.Equal(t, tc.expected, referencedNamespaces(export))
+		})
+	}
+}
+
+func TestSourceIndexValues(t *testing.T) {
+	redisRef := gdpv1beta1.ResourceRef{APIVersion: "redis.cnrm.cloud.google.com/v1beta1", Kind: "RedisInstance", Name: "cache"}
+	export := &gdpv1beta1.ResourceFieldExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "export", Namespace: "app"},
+		Spec: gdpv1beta1.ResourceFieldExportSpec{Sources: []gdpv1beta1.Source{
+			{Alias: "local", ResourceRef: redisRef},
+			{Alias: "own", ResourceRef: withNamespace(redisRef, "app")},
+			{Alias: "platform", ResourceRef: withNamespace(redisRef, "platform")},
+			{Alias: "invalid", ResourceRef: gdpv1beta1.ResourceRef{APIVersion: "redis.cnrm.cloud.google.com/v1beta1", Kind: "RedisInstance"}},
+		}},
+	}
+	require.Equal(t, []string{"RedisInstance/cache", "RedisInstance/cache", "platform/RedisInstance/cache"}, sourceIndexValues(export))
+}
+
+func TestPermits(t *testing.T) {
+	grant := &gdpv1beta1.FieldExportGrant{Spec: gdpv1beta1.FieldExportGrantSpec{
+		From: []gdpv1beta1.GrantFrom{{Namespace: "payments"}, {Namespace: "checkout"}},
+		To: []gdpv1beta1.GrantTo{
+			{Group: "redis.cnrm.cloud.google.com", Kind: "RedisInstance"},
+			{Kind: "Secret", Name: "credentials"},
+		},
+	}}
+	require.True(t, grant.Permits("checkout", "redis.cnrm.cloud.google.com", "RedisInstance", "cache"))
+	require.True(t, grant.Permits("payments", "", "Secret", "credentials"))
+	require.False(t, grant.Permits("payments", "", "Secret", "other"))
+	require.False(t, grant.Permits("payments", "", "ConfigMap", "credentials"))
+	require.False(t, grant.Permits("payments", "sql.cnrm.cloud.google.com", "RedisInstance", "cache"))
+	require.False(t, grant.Permits("search", "redis.cnrm.cloud.google.com", "RedisInstance", "cache"))
+}
+
+func TestFindGrantedExports(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, gdpv1beta1.AddToScheme(scheme))
+	redisRef := gdpv1beta1.ResourceRef{APIVersion: "redis.cnrm.cloud.google.com/v1beta1", Kind: "RedisInstance", Name: "cache"}
+	export := func(namespace string, from gdpv1beta1.ResourceRef) client.Object {
+		return &gdpv1beta1.ResourceFieldExport{
+			ObjectMeta: metav1.ObjectMeta{Name: "export", Namespace: namespace},
+			Spec: gdpv1beta1.ResourceFieldExportSpec{
+				From: &from,
+				To:   gdpv1beta1.DestinationRef{Type: gdpv1beta1.ConfigMap, Name: "config"},
+			},
+		}
+	}
+	r := &Reconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(
+				export("payments", withNamespace(redisRef, "platform")),
+				export("checkout", withNamespace(redisRef, "platform")),
+				export("search", redisRef),
+			).
+			WithIndex(&gdpv1beta1.ResourceFieldExport{}, namespaceField, func(obj client.Object) []string {
+				return referencedNamespaces(obj.(*gdpv1beta1.ResourceFieldExport))
+			}).
+			Build(),
+		ClusterGrantNamespace: "field-exporter-system",
+	}
+	grant := func(namespace string, from ...string) *gdpv1beta1.FieldExportGrant {
+		g := &gdpv1beta1.FieldExportGrant{ObjectMeta: metav1.ObjectMeta{Name: "grant", Namespace: namespace}}
+		for _, f := range from {
+			g.Spec.From = append(g.Spec.From, gdpv1beta1.GrantFrom{Namespace: f})
+		}
+		return g
+	}
+	request := func(namespace string) reconcile.Request {
+		return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: "export"}}
+	}
+	ctx := context.Background()
+
+	// checkout was removed from the grant, its export must notice it lost the grant
+	require.ElementsMatch(t, []reconcile.Request{request("payments"), request("checkout")},
+		r.findGrantedExports(ctx, grant("platform", "payments")))
+	require.Empty(t, r.findGrantedExports(ctx, grant("shared", "payments")))
+
+	// exports of any namespace a cluster grant allows may read from a cluster scoped source
+	require.ElementsMatch(t, []reconcile.Request{request("search")},
+		r.findGrantedExports(ctx, grant("field-exporter-system", "search")))
+
+	// the old cluster grant of an update returns the exports of the removed namespaces
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+	handler.EnqueueRequestsFromMapFunc(r.findGrantedExports).Update(ctx, event.UpdateEvent{
+		ObjectOld: grant("field-exporter-system", "search", "payments"),
+		ObjectNew: grant("field-exporter-system", "search"),
+	}, queue)
+	require.Equal(t, 2, queue.Len())
+}
+
+func withNamespace(ref gdpv1beta1.ResourceRef, namespace string) gdpv1beta1.ResourceRef {
+	ref.Namespace = namespace
+	return ref
+}
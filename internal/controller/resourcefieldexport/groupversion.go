@@ -2,21 +2,14 @@ package resourcefieldexport
 
 import (
 	"fmt"
-	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
-	"github.com/deliveryhero/field-exporter/api/v1alpha1"
+	"github.com/deliveryhero/field-exporter/api/v1beta1"
+	"github.com/deliveryhero/field-exporter/internal/resourcemanager"
 )
 
-var (
-	supportedGroupSuffixes = []string{
-		"cnrm.cloud.google.com",
-		"services.k8s.aws",
-	}
-)
-
-func groupVersion(from v1alpha1.ResourceRef) (string, string, error) {
+func groupVersion(from v1beta1.ResourceRef, allowlist resourcemanager.SourceAllowlist) (string, string, error) {
 	fromAPIVersion := from.APIVersion
 
 	gv, err := schema.ParseGroupVersion(fromAPIVersion)
@@ -28,10 +21,8 @@ func groupVersion(from v1alpha1.ResourceRef) (string, string, error) {
 		return "", "", fmt.Errorf("apiVersion %s is invalid", fromAPIVersion)
 	}
 
-	for _, suffix := range supportedGroupSuffixes {
-		if strings.HasSuffix(gv.Group, suffix) {
-			return gv.Group, gv.Version, nil
-		}
+	if err := allowlist.CheckKind(gv.WithKind(from.Kind)); err != nil {
+		return "", "", fmt.Errorf("unsupported apiVersion: %s, %w", fromAPIVersion, err)
 	}
-	return "", "", fmt.Errorf("unsupported apiVersion: %s, needs to be part of %v", fromAPIVersion, supportedGroupSuffixes)
+	return gv.Group, gv.Version, nil
 }
@@ -3,21 +3,23 @@ package resourcefieldexport
 import (
 	"testing"
 
-	gdpv1alpha1 "github.com/deliveryhero/field-exporter/api/v1alpha1"
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
+	"github.com/deliveryhero/field-exporter/internal/resourcemanager"
 	"github.com/stretchr/testify/require"
 )
 
 func TestGroupVersion(t *testing.T) {
 	for _, tc := range []struct {
 		name          string
-		input         gdpv1alpha1.ResourceRef
+		input         gdpv1beta1.ResourceRef
+		groups        resourcemanager.Groups
 		expectGroup   string
 		expectVersion string
 		expectErr     string
 	}{
 		{
 			name: "gcp bucket",
-			input: gdpv1alpha1.ResourceRef{
+			input: gdpv1beta1.ResourceRef{
 				APIVersion: "storage.cnrm.cloud.google.com/v1alpha1",
 				Kind:       "Bucket",
 			},
@@ -26,28 +28,53 @@ func TestGroupVersion(t *testing.T) {
 		},
 		{
 			name: "aws rds dbcluster",
-			input: gdpv1alpha1.ResourceRef{
+			input: gdpv1beta1.ResourceRef{
 				APIVersion: "rds.services.k8s.aws/v1alpha1",
 				Kind:       "DBCluster",
 			},
 			expectGroup:   "rds.services.k8s.aws",
 			expectVersion: "v1alpha1",
 		},
+		{
+			name: "azure postgresql flexible server",
+			input: gdpv1beta1.ResourceRef{
+				APIVersion: "dbforpostgresql.azure.com/v1api20221201",
+				Kind:       "FlexibleServer",
+			},
+			expectGroup:   "dbforpostgresql.azure.com",
+			expectVersion: "v1api20221201",
+		},
 		{
 			name: "malformed apiVersion",
-			input: gdpv1alpha1.ResourceRef{
+			input: gdpv1beta1.ResourceRef{
 				APIVersion: "storage.cnrm.cloud.google.com",
 			},
 			expectErr: "apiVersion storage.cnrm.cloud.google.com is invalid",
 		},
 		{
 			name:      "unsupported resource",
-			input:     gdpv1alpha1.ResourceRef{APIVersion: "unsupported.group/v1"},
-			expectErr: "unsupported apiVersion: unsupported.group/v1, needs to be part of [cnrm.cloud.google.com services.k8s.aws]",
+			input:     gdpv1beta1.ResourceRef{APIVersion: "unsupported.group/v1"},
+			expectErr: "unsupported apiVersion: unsupported.group/v1, group must match one of [alloydb.cnrm.cloud.google.com",
+		},
+		{
+			name:      "group not in the defaults",
+			input:     gdpv1beta1.ResourceRef{APIVersion: "compute.cnrm.cloud.google.com/v1beta1"},
+			expectErr: "unsupported apiVersion: compute.cnrm.cloud.google.com/v1beta1",
+		},
+		{
+			name:          "group matching a configured pattern",
+			input:         gdpv1beta1.ResourceRef{APIVersion: "compute.cnrm.cloud.google.com/v1beta1"},
+			groups:        resourcemanager.Groups{"*.cnrm.cloud.google.com"},
+			expectGroup:   "compute.cnrm.cloud.google.com",
+			expectVersion: "v1beta1",
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			group, version, err := groupVersion(tc.input)
+			groups := tc.groups
+			if groups == nil {
+				groups = resourcemanager.DefaultGroups
+			}
+			group, version, err := groupVersion(tc.input, groups)
 			if tc.expectErr != "" {
 				require.ErrorContains(t, err, tc.expectErr)
 				return
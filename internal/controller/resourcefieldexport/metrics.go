@@ -0,0 +1,15 @@
+package resourcefieldexport
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var watchedKinds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "field_exporter_watched_kinds",
+	Help: "Source kinds with a running informer, only kinds at least one export reads from are watched.",
+}, []string{"group", "version", "kind"})
+
+func init() {
+	metrics.Registry.MustRegister(watchedKinds)
+}
@@ -2,74 +2,274 @@ package resourcefieldexport
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
 )
 
-func (r *Reconciler) writeToSecret(ctx context.Context, name string, namespace string, values map[string]string) error {
+// fieldManager is the prefix of the field manager used for server-side apply. Every export
+// applies with its own field manager, so the keys it owns show up in the managedFields of the
+// destination and exports sharing a destination don't remove each other's keys.
+const fieldManager = "field-exporter"
+
+// fieldManagerMaxLength is the longest field manager the API server accepts.
+const fieldManagerMaxLength = 128
+
+// legacyFieldManager owns the keys the controller wrote with Update before it used server-side
+// apply, the API server derives it from the name of the manager binary.
+const legacyFieldManager = "manager"
+
+func fieldOwner(export *gdpv1beta1.ResourceFieldExport) client.FieldOwner {
+	return destinationFieldOwner(export, destinationNamespace(export))
+}
+
+// destinationFieldOwner returns the field manager of the export for a destination in namespace.
+func destinationFieldOwner(export *gdpv1beta1.ResourceFieldExport, namespace string) client.FieldOwner {
+	owner := fieldManager + "/" + export.Name
+	// exports of other namespaces writing the same destination may share a name
+	if namespace != export.Namespace {
+		owner = fieldManager + "/" + export.Namespace + "/" + export.Name
+	}
+	if len(owner) > fieldManagerMaxLength {
+		// long names are truncated, the hash keeps exports sharing a prefix apart
+		sum := sha256.Sum256([]byte(export.Namespace + "/" + export.Name))
+		suffix := hex.EncodeToString(sum[:8])
+		owner = owner[:fieldManagerMaxLength-len(suffix)-1] + "-" + suffix
+	}
+	return client.FieldOwner(owner)
+}
+
+// writeDestination server-side applies the values to the destination of the export. Keys the
+// export applied before and no longer declares are released by the apply, the API server removes
+// them unless another field manager co-owns them.
+func (r *Reconciler) writeDestination(ctx context.Context, export *gdpv1beta1.ResourceFieldExport, values map[string]string) error {
 	logger := log.FromContext(ctx)
-	var targetSecret v1.Secret
-	err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, &targetSecret)
+	name, namespace := export.Spec.To.Name, destinationNamespace(export)
+
+	existing, err := destinationObject(export.Spec.To.Type)
 	if err != nil {
-		// todo: disambiguate on type of error
-		logger.Error(err, "failed to get target Secret",
+		return err
+	}
+	var controlled bool
+	err = r.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, existing)
+	switch {
+	case apierrors.IsNotFound(err) && export.Spec.To.CreatePolicy == gdpv1beta1.CreateIfMissing:
+		controlled = true
+	case err != nil:
+		logger.Error(err, "failed to get destination",
+			"type", export.Spec.To.Type,
 			"name", name,
 			"namespace", namespace)
 		return err
+	default:
+		controlled = metav1.IsControlledBy(existing, export)
+	}
+
+	applied := applyConfiguration(export, values)
+	if controlled {
+		// the controller reference must be part of every apply, otherwise it would be released
+		if err := controllerutil.SetControllerReference(export, applied, r.Scheme); err != nil {
+			return err
+		}
 	}
-	secretCopy := targetSecret.DeepCopy()
-	if secretCopy.Data == nil {
-		secretCopy.Data = make(map[string][]byte)
+
+	err = r.Patch(ctx, applied, client.Apply, fieldOwner(export))
+	if apierrors.IsConflict(err) && previouslyExported(err, writtenKeys(export)) {
+		// keys written by this export before it used server-side apply are still owned by the
+		// field manager of that update, the export takes them over once. Conflicts with any other
+		// field manager are reported, even on keys the export wrote before.
+		logger.Info("taking over previously exported keys",
+			"type", export.Spec.To.Type,
+			"name", name,
+			"namespace", namespace)
+		err = r.Patch(ctx, applied, client.Apply, fieldOwner(export), client.ForceOwnership)
 	}
-	for k, v := range values {
-		secretCopy.Data[k] = []byte(v)
+	if apierrors.IsConflict(err) {
+		return fmt.Errorf("keys of %s %s are managed by another field manager: %w", export.Spec.To.Type, name, err)
 	}
-	err = r.Update(ctx, secretCopy)
 	if err != nil {
-		logger.Error(err, "failed to update Secret",
+		logger.Error(err, "failed to apply destination",
+			"type", export.Spec.To.Type,
 			"name", name,
 			"namespace", namespace,
 			"keyCount", len(values))
 		return err
 	}
-	logger.Info("successfully updated Secret",
+
+	logger.Info("successfully applied destination",
+		"type", export.Spec.To.Type,
 		"name", name,
 		"namespace", namespace,
 		"keyCount", len(values))
 	return nil
 }
 
-func (r *Reconciler) writeToConfigMap(ctx context.Context, name string, namespace string, values map[string]string) error {
+// cleanupDestination removes the exported keys from the destination they were written to. The
+// destination `to` refers to now is cleaned up as well, in case the keys were written to it but
+// not yet recorded.
+func (r *Reconciler) cleanupDestination(ctx context.Context, export *gdpv1beta1.ResourceFieldExport) error {
+	current := exportedDestination(export)
+	if previous := export.Status.ExportedTo; previous != nil && *previous != current {
+		if err := r.releaseDestination(ctx, export, *previous); err != nil {
+			return err
+		}
+	}
+	return r.releaseDestination(ctx, export, current)
+}
+
+// releaseDestination removes the keys of the export from a destination. A destination created by
+// the controller is deleted as a whole. Any other is applied without keys by the field manager of
+// the export, so the API server removes only the keys the export owns alone and leaves those of
+// other field managers, including keys they co-own.
+func (r *Reconciler) releaseDestination(ctx context.Context, export *gdpv1beta1.ResourceFieldExport, destination gdpv1beta1.ExportedDestination) error {
 	logger := log.FromContext(ctx)
-	var targetConfigMap v1.ConfigMap
-	err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, &targetConfigMap)
+	existing, err := destinationObject(destination.Type)
 	if err != nil {
-		// todo: disambiguate on type of error
-		logger.Error(err, "failed to get target ConfigMap",
-			"name", name,
-			"namespace", namespace)
 		return err
 	}
-	cmCopy := targetConfigMap.DeepCopy()
-	if cmCopy.Data == nil {
-		cmCopy.Data = make(map[string]string)
+	err = r.Get(ctx, client.ObjectKey{Name: destination.Name, Namespace: destination.Namespace}, existing)
+	if err != nil {
+		return client.IgnoreNotFound(err)
 	}
-	for k, v := range values {
-		cmCopy.Data[k] = v
+
+	if metav1.IsControlledBy(existing, export) {
+		if err := r.Delete(ctx, existing); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		logger.Info("deleted destination",
+			"type", destination.Type,
+			"name", destination.Name,
+			"namespace", destination.Namespace)
+		return nil
 	}
-	err = r.Update(ctx, cmCopy)
-	if err != nil {
-		logger.Error(err, "failed to update ConfigMap",
-			"name", name,
-			"namespace", namespace,
-			"keyCount", len(values))
+
+	released := destinationConfiguration(destination, nil)
+	// the apply must not create a destination deleted in the meantime
+	released.SetResourceVersion(existing.GetResourceVersion())
+	err = r.Patch(ctx, released, client.Apply, destinationFieldOwner(export, destination.Namespace))
+	if client.IgnoreNotFound(err) != nil {
 		return err
 	}
-	logger.Info("successfully updated ConfigMap",
-		"name", name,
-		"namespace", namespace,
-		"keyCount", len(values))
+	logger.Info("released exported keys of destination",
+		"type", destination.Type,
+		"name", destination.Name,
+		"namespace", destination.Namespace)
 	return nil
 }
+
+func destinationObject(destinationType gdpv1beta1.DestinationType) (client.Object, error) {
+	switch destinationType {
+	case gdpv1beta1.Secret:
+		return &v1.Secret{}, nil
+	case gdpv1beta1.ConfigMap:
+		return &v1.ConfigMap{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported destination type: %s", destinationType)
+	}
+}
+
+// applyConfiguration returns the destination with only the fields owned by the export.
+func applyConfiguration(export *gdpv1beta1.ResourceFieldExport, values map[string]string) client.Object {
+	return destinationConfiguration(exportedDestination(export), values)
+}
+
+// destinationConfiguration returns the ConfigMap or Secret holding only the values, without
+// data when values is nil.
+func destinationConfiguration(destination gdpv1beta1.ExportedDestination, values map[string]string) client.Object {
+	meta := metav1.ObjectMeta{Name: destination.Name, Namespace: destination.Namespace}
+	if destination.Type == gdpv1beta1.Secret {
+		var data map[string][]byte
+		if values != nil {
+			data = make(map[string][]byte, len(values))
+		}
+		for k, v := range values {
+			data[k] = []byte(v)
+		}
+		return &v1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: meta,
+			Data:       data,
+		}
+	}
+	return &v1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: meta,
+		Data:       values,
+	}
+}
+
+// previouslyExported reports whether every field of an apply conflict is a key the export
+// has written before with Update, i.e. is owned by the legacy field manager.
+func previouslyExported(err error, exported []string) bool {
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) || status.Status().Details == nil || len(status.Status().Details.Causes) == 0 {
+		return false
+	}
+	fields := make(map[string]struct{}, len(exported))
+	for _, k := range exported {
+		fields[".data."+k] = struct{}{}
+	}
+	legacy := fmt.Sprintf("conflict with %q", legacyFieldManager)
+	for _, c := range status.Status().Details.Causes {
+		if _, ok := fields[c.Field]; !ok {
+			return false
+		}
+		// the message names the manager, e.g. `conflict with "manager" using v1`
+		if c.Message != legacy && !strings.HasPrefix(c.Message, legacy+" ") {
+			return false
+		}
+	}
+	return true
+}
+
+// exportedKeys returns the sorted keys of the values written to the destination.
+func exportedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// exportedDestination is the destination `to` refers to.
+func exportedDestination(export *gdpv1beta1.ResourceFieldExport) gdpv1beta1.ExportedDestination {
+	return gdpv1beta1.ExportedDestination{
+		Type:      export.Spec.To.Type,
+		Name:      export.Spec.To.Name,
+		Namespace: destinationNamespace(export),
+	}
+}
+
+// writtenKeys returns the keys the export last wrote to the destination `to` refers to, none when
+// they were written to another destination.
+func writtenKeys(export *gdpv1beta1.ResourceFieldExport) []string {
+	if to := export.Status.ExportedTo; to != nil && *to != exportedDestination(export) {
+		return nil
+	}
+	return export.Status.ExportedKeys
+}
+
+// staleKeys returns the previously exported keys that are not part of values anymore.
+// Only keys the export wrote itself are considered, keys written by others are left alone.
+func staleKeys(previous []string, values map[string]string) []string {
+	var stale []string
+	for _, k := range previous {
+		if _, ok := values[k]; !ok {
+			stale = append(stale, k)
+		}
+	}
+	return stale
+}
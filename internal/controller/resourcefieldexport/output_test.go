@@ -0,0 +1,192 @@
+package resourcefieldexport
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
+)
+
+func TestStaleKeys(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		previous []string
+		values   map[string]string
+		expected []string
+	}{
+		{
+			name:   "nothing exported before",
+			values: map[string]string{"host": "localhost"},
+		},
+		{
+			name:     "same keys",
+			previous: []string{"host", "port"},
+			values:   map[string]string{"host": "localhost", "port": "6379"},
+		},
+		{
+			name:     "renamed key",
+			previous: []string{"host", "port"},
+			values:   map[string]string{"endpoint": "localhost", "port": "6379"},
+			expected: []string{"host"},
+		},
+		{
+			name:     "all outputs removed",
+			previous: []string{"host", "port"},
+			values:   map[string]string{},
+			expected: []string{"host", "port"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, staleKeys(tc.previous, tc.values))
+		})
+	}
+}
+
+func TestWrittenKeys(t *testing.T) {
+	export := &gdpv1beta1.ResourceFieldExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "export", Namespace: "default"},
+		Spec: gdpv1beta1.ResourceFieldExportSpec{
+			To: gdpv1beta1.DestinationRef{Type: gdpv1beta1.ConfigMap, Name: "target"},
+		},
+		Status: gdpv1beta1.ResourceFieldExportStatus{ExportedKeys: []string{"host"}},
+	}
+	// exports synced before the destination was recorded
+	require.Equal(t, []string{"host"}, writtenKeys(export))
+
+	export.Status.ExportedTo = &gdpv1beta1.ExportedDestination{Type: gdpv1beta1.ConfigMap, Name: "target", Namespace: "default"}
+	require.Equal(t, []string{"host"}, writtenKeys(export))
+
+	for _, to := range []gdpv1beta1.DestinationRef{
+		{Type: gdpv1beta1.ConfigMap, Name: "other"},
+		{Type: gdpv1beta1.Secret, Name: "target"},
+		{Type: gdpv1beta1.ConfigMap, Name: "target", Namespace: "shared"},
+	} {
+		export.Spec.To = to
+		require.Empty(t, writtenKeys(export), to)
+	}
+}
+
+func TestExportedKeys(t *testing.T) {
+	require.Equal(t, []string{}, exportedKeys(map[string]string{}))
+	require.Equal(t, []string{"a", "b", "c"}, exportedKeys(map[string]string{"c": "3", "a": "1", "b": "2"}))
+}
+
+func TestPreviouslyExported(t *testing.T) {
+	conflict := func(fields ...string) error {
+		causes := make([]metav1.StatusCause, 0, len(fields))
+		for _, f := range fields {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldManagerConflict,
+				Message: `conflict with "manager" using v1`,
+				Field:   f,
+			})
+		}
+		return apierrors.NewApplyConflict(causes, "Apply failed with conflicts")
+	}
+	for _, tc := range []struct {
+		name     string
+		err      error
+		exported []string
+		expected bool
+	}{
+		{
+			name:     "not an api error",
+			err:      errors.New("boom"),
+			exported: []string{"host"},
+		},
+		{
+			name:     "conflict without causes",
+			err:      conflict(),
+			exported: []string{"host"},
+		},
+		{
+			name:     "conflict on exported key",
+			err:      conflict(".data.host"),
+			exported: []string{"host", "port"},
+			expected: true,
+		},
+		{
+			name:     "conflict on foreign key",
+			err:      conflict(".data.host", ".data.user"),
+			exported: []string{"host", "port"},
+		},
+		{
+			name: "conflict with another field manager on exported key",
+			err: apierrors.NewApplyConflict([]metav1.StatusCause{{
+				Type:    metav1.CauseTypeFieldManagerConflict,
+				Message: `conflict with "argocd-controller" using v1`,
+				Field:   ".data.host",
+			}}, "Apply failed with conflicts"),
+			exported: []string{"host"},
+		},
+		{
+			name:     "conflict on metadata",
+			err:      conflict(".metadata.labels.app"),
+			exported: []string{"host"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, previouslyExported(tc.err, tc.exported))
+		})
+	}
+}
+
+func TestApplyConfiguration(t *testing.T) {
+	export := &gdpv1beta1.ResourceFieldExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "export", Namespace: "default"},
+		Spec: gdpv1beta1.ResourceFieldExportSpec{
+			To: gdpv1beta1.DestinationRef{Type: gdpv1beta1.Secret, Name: "target"},
+		},
+	}
+	secret, ok := applyConfiguration(export, map[string]string{"host": "localhost"}).(*corev1.Secret)
+	require.True(t, ok)
+	require.Equal(t, "Secret", secret.Kind)
+	require.Equal(t, "target", secret.Name)
+	require.Equal(t, "default", secret.Namespace)
+	require.Equal(t, map[string][]byte{"host": []byte("localhost")}, secret.Data)
+
+	export.Spec.To.Type = gdpv1beta1.ConfigMap
+	cm, ok := applyConfiguration(export, map[string]string{"host": "localhost"}).(*corev1.ConfigMap)
+	require.True(t, ok)
+	require.Equal(t, "ConfigMap", cm.Kind)
+	require.Equal(t, map[string]string{"host": "localhost"}, cm.Data)
+	require.Equal(t, client.FieldOwner("field-exporter/export"), fieldOwner(export))
+
+	// exports of other namespaces may share the name of the export
+	export.Spec.To.Namespace = "shared"
+	cm, ok = applyConfiguration(export, map[string]string{"host": "localhost"}).(*corev1.ConfigMap)
+	require.True(t, ok)
+	require.Equal(t, "shared", cm.Namespace)
+	require.Equal(t, client.FieldOwner("field-exporter/default/export"), fieldOwner(export))
+	require.Equal(t, client.FieldOwner("field-exporter/export"), destinationFieldOwner(export, "default"))
+
+	// the previous destination is released without data
+	secret, ok = destinationConfiguration(gdpv1beta1.ExportedDestination{Type: gdpv1beta1.Secret, Name: "old", Namespace: "default"}, nil).(*corev1.Secret)
+	require.True(t, ok)
+	require.Equal(t, "old", secret.Name)
+	require.Nil(t, secret.Data)
+}
+
+func TestFieldOwnerLength(t *testing.T) {
+	export := &gdpv1beta1.ResourceFieldExport{
+		ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 253), Namespace: "default"},
+		Spec: gdpv1beta1.ResourceFieldExportSpec{
+			To: gdpv1beta1.DestinationRef{Type: gdpv1beta1.ConfigMap, Name: "target", Namespace: "shared"},
+		},
+	}
+	owner := fieldOwner(export)
+	require.Len(t, owner, fieldManagerMaxLength)
+	require.True(t, strings.HasPrefix(string(owner), "field-exporter/default/aaa"))
+	require.Equal(t, owner, fieldOwner(export), "the field manager must be stable")
+
+	other := export.DeepCopy()
+	other.Name = strings.Repeat("a", 252) + "b"
+	require.NotEqual(t, owner, fieldOwner(other))
+}
@@ -2,11 +2,22 @@ package resourcefieldexport
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
+	"strconv"
 
 	"github.com/itchyny/gojq"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
+	"github.com/deliveryhero/field-exporter/internal/render"
 )
 
+// errNoResults is returned when a query resolves to nothing or to null only
+var errNoResults = errors.New("no results returned")
+
 func fieldValues(ctx context.Context, input map[string]interface{}, queryString string) (any, error) {
 	query, err := gojq.Parse(queryString)
 	if err != nil {
@@ -29,7 +40,7 @@ func fieldValues(ctx context.Context, input map[string]interface{}, queryString
 		results = append(results, value)
 	}
 	if len(results) == 0 {
-		return "", fmt.Errorf("no results returned for query %s", queryString)
+		return "", fmt.Errorf("%w for query %s", errNoResults, queryString)
 	}
 
 	if len(results) != 1 {
@@ -45,14 +56,125 @@ func fieldStringValue(ctx context.Context, input map[string]interface{}, query s
 		return "", err
 	}
 
+	// gojq normalizes numbers to int, float64 or *big.Int, the remaining types cover values
+	// that did not pass through gojq
 	switch x := result.(type) {
 	case string:
 		return x, nil
 	case int:
-		return fmt.Sprintf("%d", x), nil
+		return strconv.Itoa(x), nil
+	case int64:
+		return strconv.FormatInt(x, 10), nil
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), nil
+	case *big.Int:
+		return x.String(), nil
+	case json.Number:
+		return x.String(), nil
 	case bool:
-		return fmt.Sprintf("%t", x), nil
+		return strconv.FormatBool(x), nil
+	case []any, map[string]any:
+		serialized, err := json.Marshal(x)
+		if err != nil {
+			return "", fmt.Errorf("failed to serialize result of query %s: %w", query, err)
+		}
+		return string(serialized), nil
 	default:
 		return "", fmt.Errorf("unsupported data type %T for query %s", result, query)
 	}
 }
+
+// outputValue resolves the value of an output from its path, template or fields. When the path
+// resolves to null it returns the null policy that was applied, a Skip policy leaves the output
+// out of the destination.
+func outputValue(ctx context.Context, input map[string]interface{}, output gdpv1beta1.Output) (string, gdpv1beta1.NullPolicy, error) {
+	var value string
+	var err error
+	switch {
+	case output.Format != "":
+		value, err = formattedValue(ctx, input, output)
+	case output.Template != "":
+		value, err = render.Template(ctx, output.Template, input)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to render template of output %s: %w", output.Key, err)
+		}
+	default:
+		value, err = fieldStringValue(ctx, input, output.Path)
+	}
+	// a missing entry of a formatted output fails it, only a missing path falls back
+	if output.Path != "" && errors.Is(err, errNoResults) {
+		return nullValue(output, err)
+	}
+	return value, "", err
+}
+
+// nullValue applies the null policy of an output whose path resolved to null.
+func nullValue(output gdpv1beta1.Output, err error) (string, gdpv1beta1.NullPolicy, error) {
+	policy := output.NullHandling()
+	switch policy {
+	case gdpv1beta1.NullEmpty, gdpv1beta1.NullSkip:
+		return "", policy, nil
+	case gdpv1beta1.NullDefault:
+		if output.Default != nil {
+			return *output.Default, policy, nil
+		}
+	}
+	return "", "", err
+}
+
+// formattedValue serializes the object selected by the path or built from the fields of an output.
+func formattedValue(ctx context.Context, input map[string]interface{}, output gdpv1beta1.Output) (string, error) {
+	var value any
+	if len(output.Fields) > 0 {
+		fields := make(map[string]any, len(output.Fields))
+		for _, f := range output.Fields {
+			fieldValue, err := fieldValues(ctx, input, f.Path)
+			if err != nil {
+				return "", fmt.Errorf("failed to get field %s of output %s: %w", f.Key, output.Key, err)
+			}
+			fields[f.Key] = fieldValue
+		}
+		value = fields
+	} else {
+		var err error
+		value, err = fieldValues(ctx, input, output.Path)
+		if err != nil {
+			return "", err
+		}
+	}
+	formatted, err := render.Format(value, string(output.Format))
+	if err != nil {
+		return "", fmt.Errorf("failed to format output %s: %w", output.Key, err)
+	}
+	return formatted, nil
+}
+
+// resolveOutputs resolves every output against the input and returns the values to write. All
+// outputs are resolved before failing, so the results report every broken output.
+func resolveOutputs(ctx context.Context, input map[string]interface{}, outputs []gdpv1beta1.Output) (map[string]string, []gdpv1beta1.OutputFallback, []outputResult, error) {
+	logger := log.FromContext(ctx)
+	values := make(map[string]string)
+	var fallbacks []gdpv1beta1.OutputFallback
+	results := make([]outputResult, 0, len(outputs))
+	var errs []error
+	for _, output := range outputs {
+		value, fallback, err := outputValue(ctx, input, output)
+		results = append(results, outputResult{key: output.Key, value: value, fallback: fallback, err: err})
+		if err != nil {
+			logger.Error(err, "failed to extract field value",
+				"path", output.Path,
+				"template", output.Template,
+				"key", output.Key)
+			errs = append(errs, err)
+			continue
+		}
+		if fallback != "" {
+			logger.Info("output path resolved to null", "key", output.Key, "policy", fallback)
+			fallbacks = append(fallbacks, gdpv1beta1.OutputFallback{Key: output.Key, Policy: fallback})
+		}
+		if fallback != gdpv1beta1.NullSkip {
+			values[output.Key] = value
+		}
+	}
+	return values, fallbacks, results, errors.Join(errs...)
+}
@@ -2,9 +2,18 @@ package resourcefieldexport
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
 )
 
 func TestFieldValues(t *testing.T) {
@@ -91,16 +100,46 @@ func TestFieldValue(t *testing.T) {
 			expectResult: "8",
 		},
 		{
-			name:         "int value",
+			name:         "bool value",
 			input:        map[string]any{"status": map[string]any{"available": true}},
 			query:        ".status.available",
 			expectResult: "true",
 		},
 		{
-			name:      "error case",
-			input:     map[string]any{"status": map[string]any{"available": true}},
-			query:     ".status",
-			expectErr: `unsupported data type map[string]interface {} for query .status`,
+			name:         "int64 value",
+			input:        map[string]any{"status": map[string]any{"size": int64(10737418240)}},
+			query:        ".status.size",
+			expectResult: "10737418240",
+		},
+		{
+			name:         "float value",
+			input:        map[string]any{"status": map[string]any{"ratio": 0.75}},
+			query:        ".status.ratio",
+			expectResult: "0.75",
+		},
+		{
+			name:         "large float value",
+			input:        map[string]any{"status": map[string]any{"bytes": 1e21}},
+			query:        ".status.bytes",
+			expectResult: "1000000000000000000000",
+		},
+		{
+			name:         "array value",
+			input:        map[string]any{"status": map[string]any{"zones": []any{"a", "b"}}},
+			query:        ".status.zones",
+			expectResult: `["a","b"]`,
+		},
+		{
+			name:         "object value",
+			input:        map[string]any{"status": map[string]any{"available": true}},
+			query:        ".status",
+			expectResult: `{"available":true}`,
+		},
+		{
+			name:      "null value",
+			input:     map[string]any{"status": map[string]any{"host": nil}},
+			query:     ".status.host",
+			expectErr: "no results returned for query .status.host",
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
@@ -115,3 +154,313 @@ func TestFieldValue(t *testing.T) {
 		})
 	}
 }
+
+func TestOutputValue(t *testing.T) {
+	input := map[string]any{"status": map[string]any{"host": "10.0.0.1", "port": 6379}}
+	for _, tc := range []struct {
+		name           string
+		output         gdpv1beta1.Output
+		expectResult   string
+		expectFallback gdpv1beta1.NullPolicy
+		expectErr      string
+	}{
+		{
+			name:         "path",
+			output:       gdpv1beta1.Output{Key: "host", Path: ".status.host"},
+			expectResult: "10.0.0.1",
+		},
+		{
+			name:      "null path",
+			output:    gdpv1beta1.Output{Key: "ip", Path: ".status.ip"},
+			expectErr: "no results returned for query .status.ip",
+		},
+		{
+			name:      "null path with error policy",
+			output:    gdpv1beta1.Output{Key: "ip", Path: ".status.ip", OnNull: gdpv1beta1.NullError},
+			expectErr: "no results returned for query .status.ip",
+		},
+		{
+			name:           "null path with empty policy",
+			output:         gdpv1beta1.Output{Key: "ip", Path: ".status.ip", OnNull: gdpv1beta1.NullEmpty},
+			expectResult:   "",
+			expectFallback: gdpv1beta1.NullEmpty,
+		},
+		{
+			name:           "null path with skip policy",
+			output:         gdpv1beta1.Output{Key: "ip", Path: ".status.ip", OnNull: gdpv1beta1.NullSkip},
+			expectFallback: gdpv1beta1.NullSkip,
+		},
+		{
+			name:           "null path with default policy",
+			output:         gdpv1beta1.Output{Key: "ip", Path: ".status.ip", OnNull: gdpv1beta1.NullDefault, Default: ptr.To("127.0.0.1")},
+			expectResult:   "127.0.0.1",
+			expectFallback: gdpv1beta1.NullDefault,
+		},
+		{
+			name:           "null path with default",
+			output:         gdpv1beta1.Output{Key: "ip", Path: ".status.ip", Default: ptr.To("127.0.0.1")},
+			expectResult:   "127.0.0.1",
+			expectFallback: gdpv1beta1.NullDefault,
+		},
+		{
+			name:      "null path with default and error policy",
+			output:    gdpv1beta1.Output{Key: "ip", Path: ".status.ip", OnNull: gdpv1beta1.NullError, Default: ptr.To("127.0.0.1")},
+			expectErr: "no results returned for query .status.ip",
+		},
+		{
+			name:           "optional null path",
+			output:         gdpv1beta1.Output{Key: "ip", Path: ".status.ip", Optional: true},
+			expectFallback: gdpv1beta1.NullSkip,
+		},
+		{
+			name:         "optional path",
+			output:       gdpv1beta1.Output{Key: "host", Path: ".status.host", Optional: true},
+			expectResult: "10.0.0.1",
+		},
+		{
+			name:           "optional formatted path",
+			output:         gdpv1beta1.Output{Key: "endpoint.json", Path: ".status.endpoint", Format: gdpv1beta1.JSON, Optional: true},
+			expectFallback: gdpv1beta1.NullSkip,
+		},
+		{
+			name: "optional formatted fields",
+			output: gdpv1beta1.Output{Key: ".env", Format: gdpv1beta1.Dotenv, Optional: true, Fields: []gdpv1beta1.Field{
+				{Key: "REDIS_HOST", Path: ".status.hostt"},
+			}},
+			expectErr: "failed to get field REDIS_HOST of output .env: no results returned for query .status.hostt",
+		},
+		{
+			name:         "template",
+			output:       gdpv1beta1.Output{Key: "url", Template: "redis://{{ .status.host }}:{{ .status.port }}"},
+			expectResult: "redis://10.0.0.1:6379",
+		},
+		{
+			name:   "formatted path",
+			output: gdpv1beta1.Output{Key: "status.json", Path: ".status", Format: gdpv1beta1.JSON},
+			expectResult: `{
+  "host": "10.0.0.1",
+  "port": 6379
+}
+`,
+		},
+		{
+			name: "formatted fields",
+			output: gdpv1beta1.Output{Key: ".env", Format: gdpv1beta1.Dotenv, Fields: []gdpv1beta1.Field{
+				{Key: "REDIS_HOST", Path: ".status.host"},
+				{Key: "REDIS_PORT", Path: ".status.port"},
+			}},
+			expectResult: "REDIS_HOST=10.0.0.1\nREDIS_PORT=6379\n",
+		},
+		{
+			name: "formatted fields error",
+			output: gdpv1beta1.Output{Key: ".env", Format: gdpv1beta1.Dotenv, Fields: []gdpv1beta1.Field{
+				{Key: "REDIS_HOST", Path: ".status.hostt"},
+			}},
+			expectErr: "failed to get field REDIS_HOST of output .env: no results returned for query .status.hostt",
+		},
+		{
+			name:      "formatted scalar",
+			output:    gdpv1beta1.Output{Key: ".env", Path: ".status.host", Format: gdpv1beta1.Dotenv},
+			expectErr: "failed to format output .env: format Dotenv requires an object, got string",
+		},
+		{
+			name:      "template error",
+			output:    gdpv1beta1.Output{Key: "url", Template: "redis://{{ .status.hostt }}"},
+			expectErr: `failed to render template of output url`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result, fallback, err := outputValue(context.Background(), input, tc.output)
+			if tc.expectErr != "" {
+				require.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectFallback, fallback)
+			require.Equal(t, tc.expectResult, result)
+		})
+	}
+}
+
+// TestFieldValueResources covers the field types of the resources the controller exports from,
+// decoded the way the API server returns them.
+func TestFieldValueResources(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		crd          string
+		resource     string
+		query        string
+		fieldType    string
+		expectResult string
+		expectErr    string
+	}{
+		{
+			name:         "RedisInstance port",
+			crd:          "config-connector-crds/redisinstance.yaml",
+			resource:     `{"kind":"RedisInstance","status":{"host":"10.0.0.3","port":6379,"readEndpointPort":null}}`,
+			query:        ".status.port",
+			fieldType:    "integer",
+			expectResult: "6379",
+		},
+		{
+			name:      "RedisInstance missing read endpoint",
+			crd:       "config-connector-crds/redisinstance.yaml",
+			resource:  `{"kind":"RedisInstance","status":{"host":"10.0.0.3","port":6379,"readEndpointPort":null}}`,
+			query:     ".status.readEndpointPort",
+			fieldType: "integer",
+			expectErr: "no results returned for query .status.readEndpointPort",
+		},
+		{
+			name:         "RedisInstance memory size",
+			crd:          "config-connector-crds/redisinstance.yaml",
+			resource:     `{"kind":"RedisInstance","spec":{"memorySizeGb":16}}`,
+			query:        ".spec.memorySizeGb",
+			fieldType:    "integer",
+			expectResult: "16",
+		},
+		{
+			name:         "SQLInstance ip addresses",
+			crd:          "config-connector-crds/sqlinstance.yaml",
+			resource:     `{"kind":"SQLInstance","status":{"ipAddress":[{"ipAddress":"10.1.0.5","type":"PRIVATE"}]}}`,
+			query:        ".status.ipAddress",
+			fieldType:    "array",
+			expectResult: `[{"ipAddress":"10.1.0.5","type":"PRIVATE"}]`,
+		},
+		{
+			name:         "SQLInstance connection name",
+			crd:          "config-connector-crds/sqlinstance.yaml",
+			resource:     `{"kind":"SQLInstance","status":{"connectionName":"project:europe-west1:db"}}`,
+			query:        ".status.connectionName",
+			fieldType:    "string",
+			expectResult: "project:europe-west1:db",
+		},
+		{
+			name:         "SQLInstance disk size",
+			crd:          "config-connector-crds/sqlinstance.yaml",
+			resource:     `{"kind":"SQLInstance","spec":{"settings":{"diskSize":100}}}`,
+			query:        ".spec.settings.diskSize",
+			fieldType:    "integer",
+			expectResult: "100",
+		},
+		{
+			name:         "DBInstance endpoint port",
+			crd:          "ack-crds/dbinstance.yaml",
+			resource:     `{"kind":"DBInstance","status":{"endpoint":{"address":"db.eu-west-1.rds.amazonaws.com","port":5432}}}`,
+			query:        ".status.endpoint.port",
+			fieldType:    "integer",
+			expectResult: "5432",
+		},
+		{
+			name:         "DBInstance endpoint",
+			crd:          "ack-crds/dbinstance.yaml",
+			resource:     `{"kind":"DBInstance","status":{"endpoint":{"address":"db.eu-west-1.rds.amazonaws.com","port":5432}}}`,
+			query:        ".status.endpoint",
+			fieldType:    "object",
+			expectResult: `{"address":"db.eu-west-1.rds.amazonaws.com","port":5432}`,
+		},
+		{
+			name:         "DBInstance allocated storage",
+			crd:          "ack-crds/dbinstance.yaml",
+			resource:     `{"kind":"DBInstance","spec":{"allocatedStorage":20}}`,
+			query:        ".spec.allocatedStorage",
+			fieldType:    "integer",
+			expectResult: "20",
+		},
+		{
+			name:         "DBCluster port",
+			crd:          "ack-crds/rds.services.k8s.aws_dbclusters.yaml",
+			resource:     `{"kind":"DBCluster","spec":{"port":3306},"status":{"readerEndpoint":"db.cluster-ro.eu-west-1.rds.amazonaws.com"}}`,
+			query:        ".spec.port",
+			fieldType:    "integer",
+			expectResult: "3306",
+		},
+		{
+			name:         "DBCluster fractional serverless capacity",
+			crd:          "ack-crds/rds.services.k8s.aws_dbclusters.yaml",
+			resource:     `{"kind":"DBCluster","spec":{"serverlessV2ScalingConfiguration":{"minCapacity":0.5,"maxCapacity":16}}}`,
+			query:        ".spec.serverlessV2ScalingConfiguration.minCapacity",
+			fieldType:    "number",
+			expectResult: "0.5",
+		},
+		{
+			name:         "DBCluster whole serverless capacity",
+			crd:          "ack-crds/rds.services.k8s.aws_dbclusters.yaml",
+			resource:     `{"kind":"DBCluster","spec":{"serverlessV2ScalingConfiguration":{"minCapacity":0.5,"maxCapacity":16}}}`,
+			query:        ".spec.serverlessV2ScalingConfiguration.maxCapacity",
+			fieldType:    "number",
+			expectResult: "16",
+		},
+		{
+			name:         "Table size",
+			crd:          "ack-crds/dynamodbtable.yaml",
+			resource:     `{"kind":"Table","status":{"tableSizeBytes":10737418240,"itemCount":0}}`,
+			query:        ".status.tableSizeBytes",
+			fieldType:    "integer",
+			expectResult: "10737418240",
+		},
+		{
+			name:         "Table item count",
+			crd:          "ack-crds/dynamodbtable.yaml",
+			resource:     `{"kind":"Table","status":{"tableSizeBytes":10737418240,"itemCount":0}}`,
+			query:        ".status.itemCount",
+			fieldType:    "integer",
+			expectResult: "0",
+		},
+		{
+			name:         "ReplicationGroup member clusters",
+			crd:          "ack-crds/elasticachereplicationgroup.yaml",
+			resource:     `{"kind":"ReplicationGroup","status":{"memberClusters":["cache-001","cache-002"]}}`,
+			query:        ".status.memberClusters",
+			fieldType:    "array",
+			expectResult: `["cache-001","cache-002"]`,
+		},
+		{
+			name:         "ReplicationGroup node group endpoint",
+			crd:          "ack-crds/elasticachereplicationgroup.yaml",
+			resource:     `{"kind":"ReplicationGroup","status":{"nodeGroups":[{"primaryEndpoint":{"address":"cache.use1.cache.amazonaws.com","port":6379}}]}}`,
+			query:        ".status.nodeGroups[0].primaryEndpoint.port",
+			fieldType:    "integer",
+			expectResult: "6379",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.fieldType, fixtureFieldType(t, tc.crd, tc.query), "the query must match the schema of the fixture")
+
+			// the API server decodes integers as int64, manifests read by the CLI have float64 numbers
+			var served, read map[string]any
+			require.NoError(t, json.Unmarshal([]byte(tc.resource), &served))
+			require.NoError(t, yaml.Unmarshal([]byte(tc.resource), &read))
+			for _, input := range []map[string]any{served, read} {
+				result, err := fieldStringValue(context.Background(), input, tc.query)
+				if tc.expectErr != "" {
+					require.ErrorContains(t, err, tc.expectErr)
+					continue
+				}
+				require.NoError(t, err)
+				require.Equal(t, tc.expectResult, result)
+			}
+		})
+	}
+}
+
+// fixtureFieldType returns the schema type of the field a simple query selects in a CRD of hack/.
+func fixtureFieldType(t *testing.T, crd, query string) string {
+	data, err := os.ReadFile(filepath.Join("../../../hack", crd))
+	require.NoError(t, err)
+	definition := &apiextensionsv1.CustomResourceDefinition{}
+	require.NoError(t, yaml.Unmarshal(data, definition))
+	require.NotEmpty(t, definition.Spec.Versions)
+
+	schema := definition.Spec.Versions[0].Schema.OpenAPIV3Schema
+	for _, field := range strings.Split(strings.TrimPrefix(query, "."), ".") {
+		name, index, _ := strings.Cut(field, "[")
+		property, ok := schema.Properties[name]
+		require.True(t, ok, "%s has no field %s", crd, query)
+		schema = &property
+		if index != "" {
+			require.NotNil(t, schema.Items, "%s of %s is not an array", name, crd)
+			schema = schema.Items.Schema
+		}
+	}
+	return schema.Type
+}
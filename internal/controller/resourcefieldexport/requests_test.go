@@ -0,0 +1,56 @@
+package resourcefieldexport
+
+import (
+	"net/http"
+	"sync"
+
+	"k8s.io/client-go/rest"
+)
+
+// apiRequests records the paths of the list and watch requests sent with a config
+type apiRequests struct {
+	mu      sync.Mutex
+	lists   []string
+	watches []string
+}
+
+func recordRequests(config *rest.Config) (*rest.Config, *apiRequests) {
+	requests := &apiRequests{}
+	recorded := rest.CopyConfig(config)
+	recorded.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			query := req.URL.Query()
+			requests.mu.Lock()
+			switch {
+			case req.Method != http.MethodGet:
+			case query.Get("watch") == "true":
+				requests.watches = append(requests.watches, req.URL.Path)
+			case query.Has("resourceVersion") || query.Has("limit"):
+				requests.lists = append(requests.lists, req.URL.Path)
+			}
+			requests.mu.Unlock()
+			return rt.RoundTrip(req)
+		})
+	}
+	return recorded, requests
+}
+
+// Lists returns the paths of the list requests.
+func (r *apiRequests) Lists() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.lists...)
+}
+
+// Watches returns the paths of the watch requests.
+func (r *apiRequests) Watches() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.watches...)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
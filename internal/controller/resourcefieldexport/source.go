@@ -0,0 +1,63 @@
+package resourcefieldexport
+
+import (
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
+)
+
+// exportSources returns the resources an export reads from. The source referenced by `from`
+// has no alias, its object is the query input itself.
+func exportSources(spec gdpv1beta1.ResourceFieldExportSpec) []gdpv1beta1.Source {
+	if spec.From != nil {
+		return []gdpv1beta1.Source{{ResourceRef: *spec.From}}
+	}
+	return spec.Sources
+}
+
+// sourceKinds returns the kinds an export reads from, invalid apiVersions are left out.
+func sourceKinds(spec gdpv1beta1.ResourceFieldExportSpec) []schema.GroupVersionKind {
+	var gvks []schema.GroupVersionKind
+	for _, source := range exportSources(spec) {
+		gv, err := schema.ParseGroupVersion(source.APIVersion)
+		if err != nil || gv.Group == "" || source.Kind == "" {
+			continue
+		}
+		if gvk := gv.WithKind(source.Kind); !slices.Contains(gvks, gvk) {
+			gvks = append(gvks, gvk)
+		}
+	}
+	return gvks
+}
+
+// sourceIndexValues returns the values of the source index of an export, sources in other
+// namespaces are indexed with their namespace.
+func sourceIndexValues(export *gdpv1beta1.ResourceFieldExport) []string {
+	sources := exportSources(export.Spec)
+	values := make([]string, 0, len(sources))
+	for _, source := range sources {
+		if source.Kind == "" || source.Name == "" {
+			continue
+		}
+		if namespace := sourceNamespace(export, source.ResourceRef); namespace != export.Namespace {
+			values = append(values, namespacedSourceIndexValue(namespace, source.Kind, source.Name))
+			continue
+		}
+		values = append(values, sourceIndexValue(source.Kind, source.Name))
+	}
+	return values
+}
+
+// sourceIndexValue is the value of the source index for a resource of kind with name.
+func sourceIndexValue(kind, name string) string {
+	return fmt.Sprintf("%s/%s", kind, name)
+}
+
+// namespacedSourceIndexValue is the value of the source index for a resource of kind with name
+// in another namespace than the export.
+func namespacedSourceIndexValue(namespace, kind, name string) string {
+	return fmt.Sprintf("%s/%s/%s", namespace, kind, name)
+}
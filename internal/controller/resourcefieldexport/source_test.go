@@ -0,0 +1,91 @@
+package resourcefieldexport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
+)
+
+func TestExportSources(t *testing.T) {
+	redis := gdpv1beta1.ResourceRef{
+		APIVersion: "redis.cnrm.cloud.google.com/v1beta1",
+		Kind:       "RedisInstance",
+		Name:       "cache",
+	}
+	sql := gdpv1beta1.ResourceRef{
+		APIVersion: "sql.cnrm.cloud.google.com/v1beta1",
+		Kind:       "SQLInstance",
+		Name:       "db",
+	}
+	for _, tc := range []struct {
+		name     string
+		spec     gdpv1beta1.ResourceFieldExportSpec
+		expected []gdpv1beta1.Source
+	}{
+		{
+			name:     "from",
+			spec:     gdpv1beta1.ResourceFieldExportSpec{From: &redis},
+			expected: []gdpv1beta1.Source{{ResourceRef: redis}},
+		},
+		{
+			name: "sources",
+			spec: gdpv1beta1.ResourceFieldExportSpec{Sources: []gdpv1beta1.Source{
+				{Alias: "cache", ResourceRef: redis},
+				{Alias: "db", ResourceRef: sql},
+			}},
+			expected: []gdpv1beta1.Source{
+				{Alias: "cache", ResourceRef: redis},
+				{Alias: "db", ResourceRef: sql},
+			},
+		},
+		{
+			name: "neither",
+			spec: gdpv1beta1.ResourceFieldExportSpec{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, exportSources(tc.spec))
+		})
+	}
+}
+
+func TestSourceKinds(t *testing.T) {
+	redisGVK := schema.GroupVersionKind{Group: "redis.cnrm.cloud.google.com", Version: "v1beta1", Kind: "RedisInstance"}
+	sqlGVK := schema.GroupVersionKind{Group: "sql.cnrm.cloud.google.com", Version: "v1beta1", Kind: "SQLInstance"}
+	for _, tc := range []struct {
+		name     string
+		spec     gdpv1beta1.ResourceFieldExportSpec
+		expected []schema.GroupVersionKind
+	}{
+		{
+			name: "from",
+			spec: gdpv1beta1.ResourceFieldExportSpec{From: &gdpv1beta1.ResourceRef{
+				APIVersion: "redis.cnrm.cloud.google.com/v1beta1", Kind: "RedisInstance", Name: "cache",
+			}},
+			expected: []schema.GroupVersionKind{redisGVK},
+		},
+		{
+			name: "sources of the same kind",
+			spec: gdpv1beta1.ResourceFieldExportSpec{Sources: []gdpv1beta1.Source{
+				{Alias: "primary", ResourceRef: gdpv1beta1.ResourceRef{APIVersion: "sql.cnrm.cloud.google.com/v1beta1", Kind: "SQLInstance", Name: "primary"}},
+				{Alias: "replica", ResourceRef: gdpv1beta1.ResourceRef{APIVersion: "sql.cnrm.cloud.google.com/v1beta1", Kind: "SQLInstance", Name: "replica"}},
+				{Alias: "cache", ResourceRef: gdpv1beta1.ResourceRef{APIVersion: "redis.cnrm.cloud.google.com/v1beta1", Kind: "RedisInstance", Name: "cache"}},
+			}},
+			expected: []schema.GroupVersionKind{sqlGVK, redisGVK},
+		},
+		{
+			name: "invalid apiVersions",
+			spec: gdpv1beta1.ResourceFieldExportSpec{Sources: []gdpv1beta1.Source{
+				{Alias: "core", ResourceRef: gdpv1beta1.ResourceRef{APIVersion: "v1", Kind: "Secret", Name: "secret"}},
+				{Alias: "invalid", ResourceRef: gdpv1beta1.ResourceRef{APIVersion: "a/b/c", Kind: "Thing", Name: "thing"}},
+			}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, sourceKinds(tc.spec))
+		})
+	}
+}
@@ -2,76 +2,237 @@ package resourcefieldexport
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"slices"
 
-	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/utils/ptr"
+	"k8s.io/apimachinery/pkg/types"
 	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 
-	"github.com/deliveryhero/field-exporter/api/v1alpha1"
+	"github.com/deliveryhero/field-exporter/api/v1beta1"
 )
 
-func (r *Reconciler) degradedStatus(ctx context.Context, exports *v1alpha1.ResourceFieldExport, trigger error) (controllerruntime.Result, error) {
+func (r *Reconciler) degradedStatus(ctx context.Context, exports *v1beta1.ResourceFieldExport, outputs []v1beta1.OutputStatus, reason string, trigger error) (controllerruntime.Result, error) {
 	exports = exports.DeepCopy()
-	conditions := exports.Status.Conditions
-	found := -1
-	updateNeeded := true
-	for i, c := range conditions {
-		if c.Type == readyCondition {
-			found = i
-		}
-		if c.Status == v1.ConditionFalse && c.Message != nil && *c.Message == trigger.Error() {
-			updateNeeded = false
-		}
+	updateNeeded := setReadyCondition(exports, metav1.ConditionFalse, reason, trigger.Error())
+	if !equality.Semantic.DeepEqual(exports.Status.Outputs, outputs) {
+		exports.Status.Outputs = outputs
+		updateNeeded = true
 	}
-	if found < 0 {
-		conditions = append(conditions, v1alpha1.Condition{
-			Type: readyCondition,
-		})
-		found = len(conditions) - 1
+	if exports.Status.Preview != nil {
+		exports.Status.Preview = nil
+		updateNeeded = true
 	}
 	var err error
 	if updateNeeded {
-		conditions[found].LastTransitionTime = now()
-		conditions[found].Message = ptr.To(trigger.Error())
-		conditions[found].Status = v1.ConditionFalse
-		exports.Status.Conditions = conditions
 		err = r.Status().Update(ctx, exports)
 	}
 	return controllerruntime.Result{}, errors.Join(trigger, err)
 }
 
-func (r *Reconciler) readyStatus(ctx context.Context, exports *v1alpha1.ResourceFieldExport) (controllerruntime.Result, error) {
+// notPermittedStatus reports a reference to another namespace no grant allows. It isn't retried,
+// the export is reconciled again when a grant of the namespace changes.
+func (r *Reconciler) notPermittedStatus(ctx context.Context, exports *v1beta1.ResourceFieldExport, trigger error) (controllerruntime.Result, error) {
+	logger := log.FromContext(ctx)
+	if !errors.Is(trigger, errNotPermitted) {
+		logger.Error(trigger, "failed to list FieldExportGrants")
+		return controllerruntime.Result{}, trigger
+	}
+	logger.Info("reference not permitted, waiting for a FieldExportGrant", "reason", trigger.Error())
 	exports = exports.DeepCopy()
-	conditions := exports.Status.Conditions
-	found := -1
-	updateNeeded := true
-	for i, c := range conditions {
-		if c.Type == readyCondition {
-			found = i
-		}
-		if c.Status == v1.ConditionTrue && c.Message != nil && *c.Message == "Fields Synced" {
-			updateNeeded = false
-		}
+	if setReadyCondition(exports, metav1.ConditionFalse, v1beta1.ReasonReferenceNotPermitted, trigger.Error()) {
+		return controllerruntime.Result{}, r.Status().Update(ctx, exports)
 	}
-	if found < 0 {
-		conditions = append(conditions, v1alpha1.Condition{
-			Type: readyCondition,
-		})
-		found = len(conditions) - 1
+	return controllerruntime.Result{}, nil
+}
+
+func (r *Reconciler) readyStatus(ctx context.Context, exports *v1beta1.ResourceFieldExport, keys []string, exportedTo v1beta1.ExportedDestination, fallbacks []v1beta1.OutputFallback, outputs []v1beta1.OutputStatus) (controllerruntime.Result, error) {
+	exports = exports.DeepCopy()
+	updateNeeded := setReadyCondition(exports, metav1.ConditionTrue, v1beta1.ReasonSynced, "Fields Synced")
+	if !slices.Equal(exports.Status.ExportedKeys, keys) {
+		exports.Status.ExportedKeys = keys
+		updateNeeded = true
+	}
+	if exports.Status.ExportedTo == nil || *exports.Status.ExportedTo != exportedTo {
+		exports.Status.ExportedTo = &exportedTo
+		updateNeeded = true
+	}
+	if !slices.Equal(exports.Status.Fallbacks, fallbacks) {
+		exports.Status.Fallbacks = fallbacks
+		updateNeeded = true
+	}
+	if !equality.Semantic.DeepEqual(exports.Status.Outputs, outputs) {
+		exports.Status.Outputs = outputs
+		updateNeeded = true
+	}
+	if exports.Status.Preview != nil {
+		exports.Status.Preview = nil
+		updateNeeded = true
 	}
 	var err error
 	if updateNeeded {
-		conditions[found].LastTransitionTime = now()
-		conditions[found].Message = ptr.To("Fields Synced")
-		conditions[found].Status = v1.ConditionTrue
-		exports.Status.Conditions = conditions
+		exports.Status.LastSyncTime = now()
 		err = r.Status().Update(ctx, exports)
 	}
 	return controllerruntime.Result{}, err
 }
 
+// previewStatus reports the values a dry run would write, the exported keys and the sync time
+// keep describing the destination.
+func (r *Reconciler) previewStatus(ctx context.Context, exports *v1beta1.ResourceFieldExport, preview []v1beta1.PreviewEntry, outputs []v1beta1.OutputStatus, trigger error) (controllerruntime.Result, error) {
+	exports = exports.DeepCopy()
+	reason, message := v1beta1.ReasonDryRun, "Outputs previewed, the destination is not written"
+	if trigger != nil {
+		reason, message = v1beta1.ReasonQueryFailed, trigger.Error()
+	}
+	updateNeeded := setReadyCondition(exports, metav1.ConditionFalse, reason, message)
+	if !equality.Semantic.DeepEqual(exports.Status.Outputs, outputs) {
+		exports.Status.Outputs = outputs
+		updateNeeded = true
+	}
+	if !slices.Equal(exports.Status.Preview, preview) {
+		exports.Status.Preview = preview
+		updateNeeded = true
+	}
+	var err error
+	if updateNeeded {
+		err = r.Status().Update(ctx, exports)
+	}
+	return controllerruntime.Result{}, errors.Join(trigger, err)
+}
+
+// setReadyCondition sets the Ready condition for the current generation of the export, it
+// reports whether the status changed.
+func setReadyCondition(exports *v1beta1.ResourceFieldExport, status metav1.ConditionStatus, reason, message string) bool {
+	changed := upgradeConditions(exports.Status.Conditions)
+	if exports.Status.ObservedGeneration != exports.Generation {
+		exports.Status.ObservedGeneration = exports.Generation
+		changed = true
+	}
+	return meta.SetStatusCondition(&exports.Status.Conditions, metav1.Condition{
+		Type:               v1beta1.ReadyCondition,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: exports.Generation,
+	}) || changed
+}
+
+// upgradeConditions fills the reason and transition time of conditions written before the
+// status used metav1.Condition, both are required now. It reports whether any was upgraded.
+func upgradeConditions(conditions []metav1.Condition) bool {
+	var upgraded bool
+	for i := range conditions {
+		if conditions[i].Reason == "" {
+			conditions[i].Reason = v1beta1.ReasonUpgraded
+			upgraded = true
+		}
+		if conditions[i].LastTransitionTime.IsZero() {
+			conditions[i].LastTransitionTime = *now()
+			upgraded = true
+		}
+	}
+	return upgraded
+}
+
+// outputResult is the resolved value of an output
+type outputResult struct {
+	key      string
+	value    string
+	fallback v1beta1.NullPolicy
+	err      error
+}
+
+// outputStatuses returns the state of the outputs from their results. The hash and change time
+// follow the destination, so they are only updated when the values were written, resolved
+// values that weren't written are pending.
+func outputStatuses(uid types.UID, previous []v1beta1.OutputStatus, results []outputResult, written bool) []v1beta1.OutputStatus {
+	last := make(map[string]v1beta1.OutputStatus, len(previous))
+	for _, o := range previous {
+		last[o.Key] = o
+	}
+	statuses := make([]v1beta1.OutputStatus, 0, len(results))
+	index := make(map[string]int, len(results))
+	for _, result := range results {
+		status := v1beta1.OutputStatus{
+			Key:         result.key,
+			State:       v1beta1.OutputSynced,
+			Hash:        last[result.key].Hash,
+			LastChanged: last[result.key].LastChanged,
+		}
+		switch {
+		case result.err != nil:
+			status.State = v1beta1.OutputError
+			status.LastError = result.err.Error()
+		case !written:
+			status.State = v1beta1.OutputPending
+		case result.fallback != "":
+			status.State = v1beta1.OutputMissing
+		}
+		if written {
+			var hash string
+			if result.fallback != v1beta1.NullSkip {
+				hash = valueHash(uid, result.value)
+			}
+			if hash != status.Hash {
+				status.Hash = hash
+				status.LastChanged = now()
+			}
+		}
+		// a later output with the same key overwrites the value of an earlier one
+		if i, ok := index[result.key]; ok {
+			statuses[i] = status
+			continue
+		}
+		index[result.key] = len(statuses)
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// previewEntries returns the values the results would write to the destination, values of
+// Secret destinations are left out and only hashed.
+func previewEntries(uid types.UID, destination v1beta1.DestinationType, results []outputResult) []v1beta1.PreviewEntry {
+	entries := make([]v1beta1.PreviewEntry, 0, len(results))
+	index := make(map[string]int, len(results))
+	for _, result := range results {
+		if result.err == nil && result.fallback == v1beta1.NullSkip {
+			continue
+		}
+		entry := v1beta1.PreviewEntry{Key: result.key}
+		if result.err != nil {
+			entry.Error = result.err.Error()
+		} else {
+			entry.Hash = valueHash(uid, result.value)
+			if destination != v1beta1.Secret {
+				entry.Value = result.value
+			}
+		}
+		if i, ok := index[result.key]; ok {
+			entries[i] = entry
+			continue
+		}
+		index[result.key] = len(entries)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// valueHash returns the HMAC-SHA256 of a value keyed by the UID of the export. Readers of the
+// status can't compare it to precomputed hashes of common values, nor match equal values across
+// exports.
+func valueHash(uid types.UID, value string) string {
+	mac := hmac.New(sha256.New, []byte(uid))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 func now() *metav1.Time {
 	n := metav1.Now()
 	return &n
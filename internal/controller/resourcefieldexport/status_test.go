@@ -0,0 +1,203 @@
+package resourcefieldexport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
+)
+
+// uid is the UID of the export the hashes of the status tests are keyed by
+const uid = types.UID("6f1c2a9e-3b7d-4e8a-9c41-2d5f8b0a7e13")
+
+func TestOutputStatuses(t *testing.T) {
+	changed := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	previous := []gdpv1beta1.OutputStatus{
+		{Key: "host", State: gdpv1beta1.OutputSynced, Hash: valueHash(uid, "10.0.0.1"), LastChanged: &changed},
+		{Key: "port", State: gdpv1beta1.OutputSynced, Hash: valueHash(uid, "6379"), LastChanged: &changed},
+	}
+	for _, tc := range []struct {
+		name    string
+		results []outputResult
+		written bool
+		// expected statuses, a nil LastChanged of written outputs is checked to have been updated
+		expected []gdpv1beta1.OutputStatus
+	}{
+		{
+			name: "unchanged values",
+			results: []outputResult{
+				{key: "host", value: "10.0.0.1"},
+				{key: "port", value: "6379"},
+			},
+			written:  true,
+			expected: previous,
+		},
+		{
+			name: "changed value",
+			results: []outputResult{
+				{key: "host", value: "10.0.0.2"},
+				{key: "port", value: "6379"},
+			},
+			written: true,
+			expected: []gdpv1beta1.OutputStatus{
+				{Key: "host", State: gdpv1beta1.OutputSynced, Hash: valueHash(uid, "10.0.0.2")},
+				previous[1],
+			},
+		},
+		{
+			name: "errors keep the last written hash",
+			results: []outputResult{
+				{key: "host", err: errors.New("no results returned for query .status.hostt")},
+				{key: "port", value: "6380"},
+				{key: "url", err: errors.New("failed to render template of output url")},
+			},
+			expected: []gdpv1beta1.OutputStatus{
+				{Key: "host", State: gdpv1beta1.OutputError, LastError: "no results returned for query .status.hostt", Hash: valueHash(uid, "10.0.0.1"), LastChanged: &changed},
+				{Key: "port", State: gdpv1beta1.OutputPending, Hash: valueHash(uid, "6379"), LastChanged: &changed},
+				{Key: "url", State: gdpv1beta1.OutputError, LastError: "failed to render template of output url"},
+			},
+		},
+		{
+			name: "values not written are pending",
+			results: []outputResult{
+				{key: "host", value: "10.0.0.2"},
+				{key: "port", value: "6379", fallback: gdpv1beta1.NullDefault},
+			},
+			expected: []gdpv1beta1.OutputStatus{
+				{Key: "host", State: gdpv1beta1.OutputPending, Hash: valueHash(uid, "10.0.0.1"), LastChanged: &changed},
+				{Key: "port", State: gdpv1beta1.OutputPending, Hash: valueHash(uid, "6379"), LastChanged: &changed},
+			},
+		},
+		{
+			name: "fallbacks",
+			results: []outputResult{
+				{key: "host", value: "localhost", fallback: gdpv1beta1.NullDefault},
+				{key: "port", fallback: gdpv1beta1.NullSkip},
+			},
+			written: true,
+			expected: []gdpv1beta1.OutputStatus{
+				{Key: "host", State: gdpv1beta1.OutputMissing, Hash: valueHash(uid, "localhost")},
+				{Key: "port", State: gdpv1beta1.OutputMissing},
+			},
+		},
+		{
+			name: "duplicate keys",
+			results: []outputResult{
+				{key: "host", value: "10.0.0.2"},
+				{key: "host", value: "10.0.0.1"},
+			},
+			written: true,
+			expected: []gdpv1beta1.OutputStatus{
+				previous[0],
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			statuses := outputStatuses(uid, previous, tc.results, tc.written)
+			require.Len(t, statuses, len(tc.expected))
+			for i, expected := range tc.expected {
+				if tc.written && expected.LastChanged == nil {
+					require.NotNil(t, statuses[i].LastChanged)
+					require.True(t, changed.Before(statuses[i].LastChanged))
+					statuses[i].LastChanged = nil
+				}
+				require.Equal(t, expected, statuses[i])
+			}
+		})
+	}
+}
+
+func TestPreviewEntries(t *testing.T) {
+	results := []outputResult{
+		{key: "host", value: "10.0.0.1"},
+		{key: "port", err: errors.New("no results returned for query .status.prot")},
+		{key: "read-endpoint", fallback: gdpv1beta1.NullSkip},
+		{key: "read-port", value: "6379", fallback: gdpv1beta1.NullDefault},
+		{key: "host", value: "10.0.0.2"},
+	}
+	for _, tc := range []struct {
+		name        string
+		destination gdpv1beta1.DestinationType
+		expected    []gdpv1beta1.PreviewEntry
+	}{
+		{
+			name:        "config map",
+			destination: gdpv1beta1.ConfigMap,
+			expected: []gdpv1beta1.PreviewEntry{
+				{Key: "host", Value: "10.0.0.2", Hash: valueHash(uid, "10.0.0.2")},
+				{Key: "port", Error: "no results returned for query .status.prot"},
+				{Key: "read-port", Value: "6379", Hash: valueHash(uid, "6379")},
+			},
+		},
+		{
+			name:        "secret values are redacted",
+			destination: gdpv1beta1.Secret,
+			expected: []gdpv1beta1.PreviewEntry{
+				{Key: "host", Hash: valueHash(uid, "10.0.0.2")},
+				{Key: "port", Error: "no results returned for query .status.prot"},
+				{Key: "read-port", Hash: valueHash(uid, "6379")},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, previewEntries(uid, tc.destination, results))
+		})
+	}
+}
+
+func TestValueHash(t *testing.T) {
+	plain := sha256.Sum256([]byte("6379"))
+	require.NotEqual(t, hex.EncodeToString(plain[:]), valueHash(uid, "6379"), "the hash must be keyed")
+	require.Equal(t, valueHash(uid, "6379"), valueHash(uid, "6379"))
+	require.NotEqual(t, valueHash(uid, "6379"), valueHash("other", "6379"), "exports must not share hashes")
+}
+
+func TestSetReadyCondition(t *testing.T) {
+	// status written before the export used metav1.Condition
+	legacy := `{"conditions":[{"type":"Ready","status":"True","message":"Fields Synced"}]}`
+	var status gdpv1beta1.ResourceFieldExportStatus
+	require.NoError(t, json.Unmarshal([]byte(legacy), &status))
+	export := &gdpv1beta1.ResourceFieldExport{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Status:     status,
+	}
+
+	require.True(t, setReadyCondition(export, metav1.ConditionTrue, gdpv1beta1.ReasonSynced, "Fields Synced"))
+	require.Equal(t, int64(2), export.Status.ObservedGeneration)
+	require.Len(t, export.Status.Conditions, 1)
+	ready := export.Status.Conditions[0]
+	require.Equal(t, gdpv1beta1.ReasonSynced, ready.Reason)
+	require.Equal(t, int64(2), ready.ObservedGeneration)
+	require.False(t, ready.LastTransitionTime.IsZero())
+
+	require.False(t, setReadyCondition(export, metav1.ConditionTrue, gdpv1beta1.ReasonSynced, "Fields Synced"))
+
+	export.Generation = 3
+	require.True(t, setReadyCondition(export, metav1.ConditionFalse, gdpv1beta1.ReasonQueryFailed, "no results returned for query .status.host"))
+	ready = export.Status.Conditions[0]
+	require.Equal(t, metav1.ConditionFalse, ready.Status)
+	require.Equal(t, gdpv1beta1.ReasonQueryFailed, ready.Reason)
+	require.Equal(t, int64(3), ready.ObservedGeneration)
+}
+
+func TestUpgradeConditions(t *testing.T) {
+	transitioned := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	conditions := []metav1.Condition{
+		{Type: "Ready", Status: metav1.ConditionTrue, Message: "Fields Synced"},
+		{Type: "Other", Status: metav1.ConditionFalse, Reason: "Failed", LastTransitionTime: transitioned},
+	}
+	require.True(t, upgradeConditions(conditions))
+	require.Equal(t, gdpv1beta1.ReasonUpgraded, conditions[0].Reason)
+	require.False(t, conditions[0].LastTransitionTime.IsZero())
+	require.Equal(t, "Failed", conditions[1].Reason)
+	require.Equal(t, transitioned, conditions[1].LastTransitionTime)
+	require.False(t, upgradeConditions(conditions))
+}
@@ -22,10 +22,13 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2" //nolint:revive
 	. "github.com/onsi/gomega"    //nolint:revive
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
@@ -35,7 +38,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
-	gdpv1alpha1 "github.com/deliveryhero/field-exporter/api/v1alpha1"
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
 	//+kubebuilder:scaffold:imports                                                      │
 
 	"github.com/deliveryhero/field-exporter/internal/resourcemanager"
@@ -50,8 +53,13 @@ var (
 	cancel    context.CancelFunc
 	k8sClient client.Client
 	testEnv   *envtest.Environment
+
+	resourceValidator *resourcemanager.ResourceManager
 )
 
+// clusterGrantNamespace holds the FieldExportGrants of cluster scoped sources
+const clusterGrantNamespace = "field-exporter-system"
+
 func TestControllers(t *testing.T) {
 	RegisterFailHandler(Fail)
 
@@ -69,6 +77,8 @@ var _ = BeforeSuite(func() {
 			filepath.Join("..", "..", "..", "config", "crd", "bases"),
 			filepath.Join("..", "..", "..", "hack", "config-connector-crds"),
 			filepath.Join("..", "..", "..", "hack", "ack-crds"), // Add path for AWS CRDs
+			filepath.Join("..", "..", "..", "hack", "crossplane-crds"),
+			filepath.Join("..", "..", "..", "hack", "aso-crds"),
 		},
 		ErrorIfCRDPathMissing: true,
 
@@ -87,7 +97,7 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	Expect(cfg).NotTo(BeNil())
 
-	err = gdpv1alpha1.AddToScheme(scheme.Scheme)
+	err = gdpv1beta1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
 
 	//+kubebuilder:scaffold:scheme
@@ -105,15 +115,27 @@ var _ = BeforeSuite(func() {
 	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
 	Expect(err).NotTo(HaveOccurred())
 	Expect(discoveryClient).NotTo(BeNil())
-	resourceValidator, err := resourcemanager.NewResourceManager(discoveryClient)
+	resourceValidator, err = resourcemanager.NewResourceManager(discoveryClient, resourcemanager.DefaultGroups)
 	Expect(err).NotTo(HaveOccurred())
 	Expect(resourceValidator).NotTo(BeNil())
 
-	err = (&Reconciler{
+	Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: clusterGrantNamespace}})).To(Succeed())
+	reconciler := &Reconciler{
 		Client:  k8sManager.GetClient(),
 		Scheme:  k8sManager.GetScheme(),
 		Manager: resourceValidator,
-	}).SetupWithManager(k8sManager)
+
+		ClusterGrantNamespace: clusterGrantNamespace,
+	}
+	err = reconciler.SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	// CRDs installed by the specs are picked up quickly
+	err = k8sManager.Add(&resourcemanager.Rediscovery{
+		Manager:  resourceValidator,
+		Watches:  reconciler,
+		Interval: time.Second,
+	})
 	Expect(err).ToNot(HaveOccurred())
 
 	go func() {
@@ -7,10 +7,30 @@ import (
 
 	"k8s.io/apimachinery/pkg/util/json"
 
-	gdpv1alpha1 "github.com/deliveryhero/field-exporter/api/v1alpha1"
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
+	"github.com/deliveryhero/field-exporter/internal/resourcemanager"
 )
 
-func verifyStatusConditions(ctx context.Context, objectMap map[string]any, requiredStatusConditions []gdpv1alpha1.StatusCondition) error {
+// crossplaneRequiredConditions are checked on Crossplane resources of exports without
+// requiredFields, their status is only current while they are Ready and Synced
+var crossplaneRequiredConditions = []gdpv1beta1.StatusCondition{
+	{Type: "Ready", Status: "True"},
+	{Type: "Synced", Status: "True"},
+}
+
+// requiredConditions returns the status conditions a source of the group must meet. Setting
+// requiredFields, even to an empty object, replaces the defaults of the group.
+func requiredConditions(spec gdpv1beta1.ResourceFieldExportSpec, group string) []gdpv1beta1.StatusCondition {
+	if spec.RequiredFields != nil {
+		return spec.RequiredFields.StatusConditions
+	}
+	if resourcemanager.CrossplaneGroups.Allows(group) {
+		return crossplaneRequiredConditions
+	}
+	return nil
+}
+
+func verifyStatusConditions(ctx context.Context, objectMap map[string]any, requiredStatusConditions []gdpv1beta1.StatusCondition) error {
 	if len(requiredStatusConditions) == 0 {
 		return nil
 	}
@@ -8,7 +8,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/util/json"
 
-	gdpv1alpha1 "github.com/deliveryhero/field-exporter/api/v1alpha1"
+	gdpv1beta1 "github.com/deliveryhero/field-exporter/api/v1beta1"
 )
 
 func TestStatusConditions(t *testing.T) {
@@ -90,7 +90,7 @@ func TestVerifyStatusConditions(t *testing.T) {
 	for _, tc := range []struct {
 		name               string
 		inputConditions    string
-		requiredConditions []gdpv1alpha1.StatusCondition
+		requiredConditions []gdpv1beta1.StatusCondition
 		expectErr          string
 	}{
 		{
@@ -100,7 +100,7 @@ func TestVerifyStatusConditions(t *testing.T) {
 		{
 			name:            "conditions empty",
 			inputConditions: `{"status":{"conditions":[]}}`,
-			requiredConditions: []gdpv1alpha1.StatusCondition{
+			requiredConditions: []gdpv1beta1.StatusCondition{
 				{
 					Type:   "Ready",
 					Status: "True",
@@ -111,7 +111,7 @@ func TestVerifyStatusConditions(t *testing.T) {
 		{
 			name:            "conditions match",
 			inputConditions: `{"status":{"conditions":[{"type":"Ready", "status":"True"}]}}`,
-			requiredConditions: []gdpv1alpha1.StatusCondition{
+			requiredConditions: []gdpv1beta1.StatusCondition{
 				{
 					Type:   "Ready",
 					Status: "True",
@@ -121,7 +121,7 @@ func TestVerifyStatusConditions(t *testing.T) {
 		{
 			name:            "conditions mismatch",
 			inputConditions: `{"status":{"conditions":[{"type":"Ready", "status":"True"}]}}`,
-			requiredConditions: []gdpv1alpha1.StatusCondition{
+			requiredConditions: []gdpv1beta1.StatusCondition{
 				{
 					Type:   "Ready",
 					Status: "True",
@@ -142,3 +142,53 @@ func TestVerifyStatusConditions(t *testing.T) {
 		})
 	}
 }
+
+func TestRequiredConditions(t *testing.T) {
+	ready := &gdpv1beta1.RequiredFields{StatusConditions: []gdpv1beta1.StatusCondition{{Type: "Ready", Status: "True"}}}
+	for _, tc := range []struct {
+		name     string
+		spec     gdpv1beta1.ResourceFieldExportSpec
+		group    string
+		expected []gdpv1beta1.StatusCondition
+	}{
+		{
+			name:  "no defaults",
+			group: "redis.cnrm.cloud.google.com",
+		},
+		{
+			name:     "configured",
+			spec:     gdpv1beta1.ResourceFieldExportSpec{RequiredFields: ready},
+			group:    "redis.cnrm.cloud.google.com",
+			expected: ready.StatusConditions,
+		},
+		{
+			name:     "crossplane defaults",
+			group:    "s3.aws.upbound.io",
+			expected: crossplaneRequiredConditions,
+		},
+		{
+			name:     "crossplane community provider",
+			group:    "ec2.aws.crossplane.io",
+			expected: crossplaneRequiredConditions,
+		},
+		{
+			name:  "crossplane core",
+			group: "apiextensions.crossplane.io",
+		},
+		{
+			name:     "crossplane configured",
+			spec:     gdpv1beta1.ResourceFieldExportSpec{RequiredFields: ready},
+			group:    "s3.aws.upbound.io",
+			expected: ready.StatusConditions,
+		},
+		{
+			name:  "crossplane opted out",
+			spec:  gdpv1beta1.ResourceFieldExportSpec{RequiredFields: &gdpv1beta1.RequiredFields{}},
+			group: "s3.aws.upbound.io",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, requiredConditions(tc.spec, tc.group))
+		})
+	}
+}
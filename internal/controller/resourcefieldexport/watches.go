@@ -0,0 +1,109 @@
+package resourcefieldexport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// Source kinds are watched lazily: an informer runs only while the kind is supported and at least
+// one export reads from it. Informers for the thousands of objects of kinds no export reads from
+// would only cost memory and list/watch load on the API server.
+
+// StartWatch is called when a kind becomes supported, it is watched if an export reads from it.
+func (r *Reconciler) StartWatch(ctx context.Context, gvk schema.GroupVersionKind) error {
+	r.watchesMu.Lock()
+	defer r.watchesMu.Unlock()
+	if len(r.references[gvk]) == 0 {
+		return nil
+	}
+	return r.startWatchLocked(ctx, gvk)
+}
+
+// StopWatch is called when a kind is no longer supported, its informer is dropped.
+func (r *Reconciler) StopWatch(ctx context.Context, gvk schema.GroupVersionKind) error {
+	r.watchesMu.Lock()
+	defer r.watchesMu.Unlock()
+	return r.stopWatchLocked(ctx, gvk)
+}
+
+// reference records the kinds an export reads from. Kinds it is the first to read from are
+// watched, those no export reads from anymore are no longer.
+func (r *Reconciler) reference(ctx context.Context, export types.NamespacedName, gvks []schema.GroupVersionKind) error {
+	r.watchesMu.Lock()
+	defer r.watchesMu.Unlock()
+	var errs []error
+	for gvk, exports := range r.references {
+		if _, ok := exports[export]; !ok || slices.Contains(gvks, gvk) {
+			continue
+		}
+		delete(exports, export)
+		if len(exports) == 0 {
+			delete(r.references, gvk)
+			errs = append(errs, r.stopWatchLocked(ctx, gvk))
+		}
+	}
+	for _, gvk := range gvks {
+		if r.references[gvk] == nil {
+			r.references[gvk] = make(map[types.NamespacedName]struct{})
+		}
+		r.references[gvk][export] = struct{}{}
+		// kinds that aren't supported yet are watched once they are, see StartWatch
+		if r.Manager.Supports(gvk) {
+			errs = append(errs, r.startWatchLocked(ctx, gvk))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Reconciler) startWatchLocked(ctx context.Context, gvk schema.GroupVersionKind) error {
+	if _, ok := r.watched[gvk]; ok {
+		return nil
+	}
+	if r.controller == nil {
+		return fmt.Errorf("controller is not set up, can't watch %s", gvk)
+	}
+	if err := r.controller.Watch(source.Kind[client.Object](
+		r.cache,
+		metadataObject(gvk),
+		handler.EnqueueRequestsFromMapFunc(r.findFieldExports),
+		predicate.ResourceVersionChangedPredicate{},
+	)); err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("started watching source kind", "gvk", gvk)
+	r.watched[gvk] = struct{}{}
+	watchedKinds.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind).Set(1)
+	return nil
+}
+
+func (r *Reconciler) stopWatchLocked(ctx context.Context, gvk schema.GroupVersionKind) error {
+	if _, ok := r.watched[gvk]; !ok {
+		return nil
+	}
+	if err := r.cache.RemoveInformer(ctx, metadataObject(gvk)); err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("stopped watching source kind", "gvk", gvk)
+	delete(r.watched, gvk)
+	watchedKinds.DeleteLabelValues(gvk.Group, gvk.Version, gvk.Kind)
+	return nil
+}
+
+// metadataObject is the object the informer of a kind is keyed by, sources are only watched
+// for their metadata.
+func metadataObject(gvk schema.GroupVersionKind) *metav1.PartialObjectMetadata {
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(gvk)
+	return obj
+}
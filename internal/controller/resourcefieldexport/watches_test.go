@@ -0,0 +1,104 @@
+package resourcefieldexport
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/deliveryhero/field-exporter/internal/resourcemanager"
+)
+
+func TestReference(t *testing.T) {
+	redisGVK := schema.GroupVersionKind{Group: "redis.cnrm.cloud.google.com", Version: "v1beta1", Kind: "RedisInstance"}
+	sqlGVK := schema.GroupVersionKind{Group: "sql.cnrm.cloud.google.com", Version: "v1beta1", Kind: "SQLInstance"}
+	certGVK := schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+	rm, err := resourcemanager.NewResourceManager(testPreferredResources{redisGVK, sqlGVK}, resourcemanager.DefaultGroups)
+	require.NoError(t, err)
+	informers := &testInformers{}
+	r := &Reconciler{
+		Manager:    rm,
+		controller: &testController{informers: informers},
+		cache:      informers,
+		watched:    make(map[schema.GroupVersionKind]struct{}),
+		references: make(map[schema.GroupVersionKind]map[types.NamespacedName]struct{}),
+	}
+	ctx := context.Background()
+	cache1 := types.NamespacedName{Namespace: "app", Name: "cache-1"}
+	cache2 := types.NamespacedName{Namespace: "other", Name: "cache-2"}
+	tls := types.NamespacedName{Namespace: "app", Name: "tls"}
+
+	// supported kinds no export reads from are not watched
+	require.NoError(t, r.StartWatch(ctx, redisGVK))
+	require.Empty(t, r.watched)
+
+	require.NoError(t, r.reference(ctx, cache1, []schema.GroupVersionKind{redisGVK}))
+	require.NoError(t, r.reference(ctx, cache2, []schema.GroupVersionKind{redisGVK}))
+	require.Equal(t, 1, informers.started)
+	require.Contains(t, r.watched, redisGVK)
+
+	// an export reading from another kind
+	require.NoError(t, r.reference(ctx, cache1, []schema.GroupVersionKind{sqlGVK}))
+	require.Equal(t, 2, informers.started)
+	require.Contains(t, r.watched, redisGVK)
+
+	// the last export reading from the kind is deleted
+	require.NoError(t, r.reference(ctx, cache2, nil))
+	require.Equal(t, []schema.GroupVersionKind{redisGVK}, informers.removed)
+	require.Equal(t, map[schema.GroupVersionKind]struct{}{sqlGVK: {}}, r.watched)
+
+	// kinds that aren't supported yet are watched once they are
+	require.NoError(t, r.reference(ctx, tls, []schema.GroupVersionKind{certGVK}))
+	require.NotContains(t, r.watched, certGVK)
+	rm.Register("certificates", map[schema.GroupVersionKind]string{certGVK: "certificates"})
+	require.NoError(t, r.StartWatch(ctx, certGVK))
+	require.Contains(t, r.watched, certGVK)
+	require.Equal(t, 3, informers.started)
+
+	require.NoError(t, r.StopWatch(ctx, certGVK))
+	require.NotContains(t, r.watched, certGVK)
+}
+
+type testPreferredResources []schema.GroupVersionKind
+
+func (r testPreferredResources) ServerPreferredResources() ([]*metav1.APIResourceList, error) {
+	lists := make([]*metav1.APIResourceList, 0, len(r))
+	for _, gvk := range r {
+		lists = append(lists, &metav1.APIResourceList{
+			GroupVersion: gvk.GroupVersion().String(),
+			APIResources: []metav1.APIResource{{Name: strings.ToLower(gvk.Kind) + "s", Kind: gvk.Kind}},
+		})
+	}
+	return lists, nil
+}
+
+// testInformers counts the watches started on the controller and the informers removed from the cache
+type testInformers struct {
+	cache.Cache
+	started int
+	removed []schema.GroupVersionKind
+}
+
+type testController struct {
+	controller.Controller
+	informers *testInformers
+}
+
+func (c *testController) Watch(source.TypedSource[reconcile.Request]) error {
+	c.informers.started++
+	return nil
+}
+
+func (i *testInformers) RemoveInformer(_ context.Context, obj client.Object) error {
+	i.removed = append(i.removed, obj.GetObjectKind().GroupVersionKind())
+	return nil
+}
@@ -0,0 +1,95 @@
+package resourcefieldexport
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive
+	. "github.com/onsi/gomega"    //nolint:revive
+	gomegatypes "github.com/onsi/gomega/types"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	"github.com/deliveryhero/field-exporter/internal/namespaces"
+)
+
+var _ = Describe("Manager restricted to namespaces", Serial, func() {
+	var watchedNamespaces []string
+
+	BeforeEach(func() {
+		suffix := rand.Intn(10000)
+		watchedNamespaces = []string{fmt.Sprintf("team-a-%03d", suffix), fmt.Sprintf("team-b-%03d", suffix)}
+		for _, name := range watchedNamespaces {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+			Expect(k8sClient.Create(ctx, namespace)).Should(Succeed())
+		}
+	})
+
+	It("should list and watch only in the watched namespaces", func() {
+		recorded, requests := recordRequests(cfg)
+		mgr, err := ctrl.NewManager(recorded, ctrl.Options{
+			Scheme:     scheme.Scheme,
+			Metrics:    metricsserver.Options{BindAddress: "0"},
+			Cache:      cache.Options{DefaultNamespaces: namespaces.DefaultNamespaces(watchedNamespaces)},
+			Controller: config.Controller{SkipNameValidation: ptr.To(true)},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		reconciler := &Reconciler{
+			Client:  mgr.GetClient(),
+			Scheme:  mgr.GetScheme(),
+			Manager: resourceValidator,
+		}
+		Expect(reconciler.SetupWithManager(mgr)).To(Succeed())
+
+		mgrCtx, mgrCancel := context.WithCancel(ctx)
+		defer mgrCancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(mgr.Start(mgrCtx)).To(Succeed())
+		}()
+		Expect(mgr.GetCache().WaitForCacheSync(mgrCtx)).To(BeTrue())
+
+		sources := []schema.GroupVersionKind{
+			{Group: "redis.cnrm.cloud.google.com", Version: "v1beta1", Kind: "RedisInstance"},
+			{Group: "dbforpostgresql.azure.com", Version: "v1api20221201", Kind: "FlexibleServer"},
+		}
+		export := types.NamespacedName{Namespace: watchedNamespaces[0], Name: "export"}
+		Expect(reconciler.reference(mgrCtx, export, sources)).To(Succeed())
+		for _, gvk := range sources {
+			_, err := mgr.GetCache().GetInformer(mgrCtx, metadataObject(gvk))
+			Expect(err).ToNot(HaveOccurred())
+		}
+		// the informers of the controller and of the sources list in every watched namespace
+		for _, name := range watchedNamespaces {
+			prefix := fmt.Sprintf("/namespaces/%s/", name)
+			Eventually(requests.Lists).Should(ContainElements(
+				HaveSuffix(prefix+"secrets"),
+				HaveSuffix(prefix+"configmaps"),
+				HaveSuffix(prefix+"resourcefieldexports"),
+				HaveSuffix(prefix+"redisinstances"),
+				HaveSuffix(prefix+"flexibleservers"),
+			))
+		}
+		Eventually(func() int {
+			return len(requests.Watches())
+		}).Should(BeNumerically(">=", len(requests.Lists())))
+
+		var inWatchedNamespace []gomegatypes.GomegaMatcher
+		for _, name := range watchedNamespaces {
+			inWatchedNamespace = append(inWatchedNamespace, ContainSubstring(fmt.Sprintf("/namespaces/%s/", name)))
+		}
+		for _, path := range append(requests.Lists(), requests.Watches()...) {
+			Expect(path).To(SatisfyAny(inWatchedNamespace...), "cluster-wide request %s", path)
+		}
+	})
+})
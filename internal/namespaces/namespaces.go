@@ -0,0 +1,106 @@
+package namespaces
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Namespaces are the namespaces the manager is restricted to, either a list of names or a label
+// selector of namespaces. It implements flag.Value for --watch-namespaces, the manager watches
+// the whole cluster when it is empty.
+type Namespaces struct {
+	Names    []string
+	Selector labels.Selector
+}
+
+// Parse parses a comma separated list of namespaces, or a label selector, e.g. team=payments,
+// when any entry isn't a valid namespace name.
+func Parse(value string) (Namespaces, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Namespaces{}, nil
+	}
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if len(validation.IsDNS1123Label(name)) > 0 {
+			names = nil
+			break
+		}
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		return Namespaces{Names: names}, nil
+	}
+	selector, err := labels.Parse(value)
+	if err != nil {
+		return Namespaces{}, fmt.Errorf("%q is neither a list of namespaces nor a label selector: %w", value, err)
+	}
+	return Namespaces{Selector: selector}, nil
+}
+
+// IsSet reports whether the manager is restricted to namespaces.
+func (n *Namespaces) IsSet() bool {
+	return len(n.Names) > 0 || n.Selector != nil
+}
+
+func (n *Namespaces) String() string {
+	if n.Selector != nil {
+		return n.Selector.String()
+	}
+	return strings.Join(n.Names, ",")
+}
+
+func (n *Namespaces) Set(value string) error {
+	namespaces, err := Parse(value)
+	if err != nil {
+		return err
+	}
+	*n = namespaces
+	return nil
+}
+
+// Resolve returns the names of the namespaces, a selector is matched against the namespaces of
+// the cluster once, namespaces labelled later are picked up by a restart. It fails when no
+// namespace matches, as an empty list would mean the whole cluster.
+func (n *Namespaces) Resolve(ctx context.Context, c client.Reader) ([]string, error) {
+	if n.Selector == nil {
+		return n.Names, nil
+	}
+	list := &corev1.NamespaceList{}
+	if err := c.List(ctx, list, client.MatchingLabelsSelector{Selector: n.Selector}); err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, namespace := range list.Items {
+		names = append(names, namespace.Name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no namespace matches %s", n.Selector)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// DefaultNamespaces restricts a cache to the namespaces, cluster-scoped objects are still
+// cached cluster-wide.
+func DefaultNamespaces(names []string) map[string]cache.Config {
+	if len(names) == 0 {
+		return nil
+	}
+	namespaces := make(map[string]cache.Config, len(names))
+	for _, name := range names {
+		namespaces[name] = cache.Config{}
+	}
+	return namespaces
+}
@@ -0,0 +1,94 @@
+package namespaces
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		value            string
+		expectedNames    []string
+		expectedSelector string
+		expectErr        string
+	}{
+		{
+			name:          "single namespace",
+			value:         "team-a",
+			expectedNames: []string{"team-a"},
+		},
+		{
+			name:          "list of namespaces",
+			value:         "team-a, team-b,team-a",
+			expectedNames: []string{"team-a", "team-b"},
+		},
+		{
+			name:             "label selector",
+			value:            "team in (payments,checkout),env!=dev",
+			expectedSelector: "env!=dev,team in (checkout,payments)",
+		},
+		{
+			name:      "invalid selector",
+			value:     "team in payments",
+			expectErr: `"team in payments" is neither a list of namespaces nor a label selector`,
+		},
+		{
+			name:  "empty",
+			value: " ",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			namespaces, err := Parse(tc.value)
+			if tc.expectErr != "" {
+				require.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedNames, namespaces.Names)
+			if tc.expectedSelector == "" {
+				require.Nil(t, namespaces.Selector)
+			} else {
+				require.Equal(t, tc.expectedSelector, namespaces.String())
+			}
+			require.Equal(t, tc.expectedNames != nil || tc.expectedSelector != "", namespaces.IsSet())
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	c := fake.NewClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments", Labels: map[string]string{"team": "payments"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "checkout", Labels: map[string]string{"team": "checkout"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+	).Build()
+	ctx := context.Background()
+
+	namespaces, err := Parse("team")
+	require.NoError(t, err)
+	names, err := namespaces.Resolve(ctx, c)
+	require.NoError(t, err)
+	require.Equal(t, []string{"team"}, names, "a plain name is a namespace, not an exists selector")
+
+	namespaces, err = Parse("team in (payments,checkout)")
+	require.NoError(t, err)
+	names, err = namespaces.Resolve(ctx, c)
+	require.NoError(t, err)
+	require.Equal(t, []string{"checkout", "payments"}, names)
+
+	namespaces, err = Parse("team=search")
+	require.NoError(t, err)
+	_, err = namespaces.Resolve(ctx, c)
+	require.EqualError(t, err, "no namespace matches team=search")
+}
+
+func TestDefaultNamespaces(t *testing.T) {
+	require.Nil(t, DefaultNamespaces(nil))
+	require.Equal(t, map[string]cache.Config{"team-a": {}, "team-b": {}}, DefaultNamespaces([]string{"team-a", "team-b"}))
+}
@@ -0,0 +1,92 @@
+package namespaces
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Deployment names the objects of the deployed manager, config/default deploys it to the
+// field-exporter-system namespace with the field-exporter- name prefix.
+type Deployment struct {
+	// Namespace is the namespace of the manager and its service account
+	Namespace string
+	// NamePrefix is the prefix of the names of its objects
+	NamePrefix string
+}
+
+// boundRoles are the ClusterRoles of config/rbac that are bound in every namespace instead of
+// the whole cluster
+var boundRoles = []string{"manager-role", "source-reader-role"}
+
+// clusterRules are the permissions the manager still needs cluster-wide, ExportableResources are
+// cluster scoped and namespaces are listed to resolve a selector
+var clusterRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{"gdp.deliveryhero.io"},
+		Resources: []string{"exportableresources"},
+		Verbs:     []string{"get", "list", "watch"},
+	},
+	{
+		APIGroups: []string{"gdp.deliveryhero.io"},
+		Resources: []string{"exportableresources/status"},
+		Verbs:     []string{"get", "patch", "update"},
+	},
+	{
+		APIGroups: []string{"apiextensions.k8s.io"},
+		Resources: []string{"customresourcedefinitions"},
+		Verbs:     []string{"get"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"namespaces"},
+		Verbs:     []string{"list"},
+	},
+}
+
+// RBAC returns the RBAC of a manager restricted to the namespaces: the manager and source reader
+// roles are bound with a RoleBinding in every namespace, and a ClusterRole grants what can't be
+// granted per namespace. They replace the ClusterRoleBindings of config/rbac.
+func RBAC(deployment Deployment, names []string) []client.Object {
+	subject := rbacv1.Subject{
+		Kind:      rbacv1.ServiceAccountKind,
+		Name:      deployment.NamePrefix + "controller-manager",
+		Namespace: deployment.Namespace,
+	}
+	clusterRole := deployment.NamePrefix + "manager-cluster-role"
+	objects := []client.Object{
+		&rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{Name: clusterRole, Labels: rbacLabels()},
+			Rules:      clusterRules,
+		},
+		&rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: deployment.NamePrefix + "manager-cluster-rolebinding", Labels: rbacLabels()},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: clusterRole},
+			Subjects:   []rbacv1.Subject{subject},
+		},
+	}
+	for _, namespace := range names {
+		for _, role := range boundRoles {
+			objects = append(objects, &rbacv1.RoleBinding{
+				TypeMeta: metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      deployment.NamePrefix + role + "binding",
+					Namespace: namespace,
+					Labels:    rbacLabels(),
+				},
+				RoleRef:  rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: deployment.NamePrefix + role},
+				Subjects: []rbacv1.Subject{subject},
+			})
+		}
+	}
+	return objects
+}
+
+func rbacLabels() map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/component": "rbac",
+		"app.kubernetes.io/part-of":   "field-exporter",
+	}
+}
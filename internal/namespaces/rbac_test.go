@@ -0,0 +1,43 @@
+package namespaces
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestRBAC(t *testing.T) {
+	deployment := Deployment{Namespace: "field-exporter-system", NamePrefix: "field-exporter-"}
+	objects := RBAC(deployment, []string{"team-a", "team-b"})
+
+	var clusterBindings, bindings []string
+	for _, object := range objects {
+		switch o := object.(type) {
+		case *rbacv1.ClusterRoleBinding:
+			clusterBindings = append(clusterBindings, o.RoleRef.Name)
+		case *rbacv1.RoleBinding:
+			bindings = append(bindings, client.ObjectKeyFromObject(o).String()+" -> "+o.RoleRef.Name)
+			require.Equal(t, []rbacv1.Subject{{
+				Kind:      "ServiceAccount",
+				Name:      "field-exporter-controller-manager",
+				Namespace: "field-exporter-system",
+			}}, o.Subjects)
+		}
+	}
+	// only the cluster role is bound cluster-wide, it grants no namespaced resource
+	require.Equal(t, []string{"field-exporter-manager-cluster-role"}, clusterBindings)
+	require.Equal(t, []string{
+		"team-a/field-exporter-manager-rolebinding -> field-exporter-manager-role",
+		"team-a/field-exporter-source-reader-rolebinding -> field-exporter-source-reader-role",
+		"team-b/field-exporter-manager-rolebinding -> field-exporter-manager-role",
+		"team-b/field-exporter-source-reader-rolebinding -> field-exporter-source-reader-role",
+	}, bindings)
+	clusterRole := objects[0].(*rbacv1.ClusterRole)
+	for _, rule := range clusterRole.Rules {
+		require.NotContains(t, rule.Resources, "secrets")
+		require.NotContains(t, rule.Resources, "configmaps")
+		require.NotContains(t, rule.Resources, "*")
+	}
+}
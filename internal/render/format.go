@@ -0,0 +1,194 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Formats a structured output value can be serialized in.
+const (
+	JSON       = "JSON"
+	YAML       = "YAML"
+	Dotenv     = "Dotenv"
+	Properties = "Properties"
+)
+
+// Format serializes a structured value as file content. Dotenv and properties files require
+// an object, nested values are serialized as compact JSON in dotenv files and flattened into
+// dotted keys in properties files.
+func Format(value any, format string) (string, error) {
+	switch format {
+	case JSON:
+		serialized, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(serialized) + "\n", nil
+	case YAML:
+		serialized, err := yaml.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		return string(serialized), nil
+	case Dotenv:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("format %s requires an object, got %T", format, value)
+		}
+		return dotenv(object)
+	case Properties:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("format %s requires an object, got %T", format, value)
+		}
+		return properties(object), nil
+	default:
+		return "", fmt.Errorf("unsupported format %s", format)
+	}
+}
+
+// IsDotenvKey reports whether the key is a valid variable name in a dotenv file.
+func IsDotenvKey(key string) bool {
+	return key != "" && (key[0] < '0' || key[0] > '9') && strings.IndexFunc(key, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_')
+	}) < 0
+}
+
+func dotenv(object map[string]any) (string, error) {
+	var out strings.Builder
+	for _, k := range sortedKeys(object) {
+		if !IsDotenvKey(k) {
+			return "", fmt.Errorf("key %q is not a valid variable name", k)
+		}
+		var value string
+		switch v := object[k].(type) {
+		case map[string]any, []any:
+			serialized, err := json.Marshal(v)
+			if err != nil {
+				return "", err
+			}
+			value = string(serialized)
+		default:
+			value = scalar(v)
+		}
+		out.WriteString(k)
+		out.WriteByte('=')
+		out.WriteString(quoteDotenv(value))
+		out.WriteByte('\n')
+	}
+	return out.String(), nil
+}
+
+// quoteDotenv double quotes values with characters that are not safe in an unquoted value.
+func quoteDotenv(value string) string {
+	if value != "" && strings.IndexFunc(value, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-.,:/@+%", r))
+	}) < 0 {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "$", `\$`, "`", "\\`")
+	return `"` + replacer.Replace(value) + `"`
+}
+
+func properties(object map[string]any) string {
+	entries := make(map[string]string)
+	flatten("", object, entries)
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var out strings.Builder
+	for _, k := range keys {
+		out.WriteString(escapeProperty(k, true))
+		out.WriteByte('=')
+		out.WriteString(escapeProperty(entries[k], false))
+		out.WriteByte('\n')
+	}
+	return out.String()
+}
+
+// flatten adds the scalar values of nested objects and lists with Spring style keys,
+// e.g. `spring.datasource.url` or `hosts[0]`.
+func flatten(prefix string, value any, entries map[string]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, nested := range v {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			flatten(key, nested, entries)
+		}
+	case []any:
+		for i, nested := range v {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), nested, entries)
+		}
+	default:
+		entries[prefix] = scalar(v)
+	}
+}
+
+// escapeProperty escapes a key or value of a Java properties file.
+func escapeProperty(s string, key bool) string {
+	var out strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			out.WriteString(`\\`)
+		case r == '\n':
+			out.WriteString(`\n`)
+		case r == '\r':
+			out.WriteString(`\r`)
+		case r == '\t':
+			out.WriteString(`\t`)
+		case r == ' ' && (key || i == 0):
+			out.WriteString(`\ `)
+		case strings.ContainsRune("=:#!", r) && (key || i == 0):
+			out.WriteByte('\\')
+			out.WriteRune(r)
+		case r > 0xffff:
+			// characters outside the basic multilingual plane are escaped as UTF-16 surrogate pairs
+			high, low := utf16.EncodeRune(r)
+			out.WriteString(fmt.Sprintf(`\u%04x\u%04x`, high, low))
+		case r > 0x7e:
+			out.WriteString(fmt.Sprintf(`\u%04x`, r))
+		default:
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+// scalar formats a scalar JSON value, numbers are written without exponent.
+func scalar(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case *big.Int:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func sortedKeys(object map[string]any) []string {
+	keys := make([]string, 0, len(object))
+	for k := range object {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
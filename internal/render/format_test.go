@@ -0,0 +1,112 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	object := map[string]any{
+		"host":  "10.0.0.1",
+		"port":  int64(5432),
+		"ratio": 0.5,
+		"tls":   true,
+		"pool":  map[string]any{"size": 10, "name": "primary pool"},
+		"hosts": []any{"a", "b"},
+	}
+	for _, tc := range []struct {
+		name      string
+		value     any
+		format    string
+		expected  string
+		expectErr string
+	}{
+		{
+			name:   "json",
+			value:  map[string]any{"host": "10.0.0.1", "port": int64(5432)},
+			format: JSON,
+			expected: `{
+  "host": "10.0.0.1",
+  "port": 5432
+}
+`,
+		},
+		{
+			name:   "yaml",
+			value:  object,
+			format: YAML,
+			expected: `host: 10.0.0.1
+hosts:
+- a
+- b
+pool:
+  name: primary pool
+  size: 10
+port: 5432
+ratio: 0.5
+tls: true
+`,
+		},
+		{
+			name:   "dotenv",
+			value:  object,
+			format: Dotenv,
+			expected: `host=10.0.0.1
+hosts="[\"a\",\"b\"]"
+pool="{\"name\":\"primary pool\",\"size\":10}"
+port=5432
+ratio=0.5
+tls=true
+`,
+		},
+		{
+			name:   "properties",
+			value:  object,
+			format: Properties,
+			expected: `host=10.0.0.1
+hosts[0]=a
+hosts[1]=b
+pool.name=primary pool
+pool.size=10
+port=5432
+ratio=0.5
+tls=true
+`,
+		},
+		{
+			name:     "properties escaping",
+			value:    map[string]any{"key with:colon": " leading space", "unicode": "grüße 😀"},
+			format:   Properties,
+			expected: "key\\ with\\:colon=\\ leading space\nunicode=gr\\u00fc\\u00dfe \\ud83d\\ude00\n",
+		},
+		{
+			name:      "dotenv with an invalid key",
+			value:     map[string]any{"db.host": "10.0.0.1"},
+			format:    Dotenv,
+			expectErr: `key "db.host" is not a valid variable name`,
+		},
+		{
+			name:      "dotenv of a scalar",
+			value:     "10.0.0.1",
+			format:    Dotenv,
+			expectErr: "format Dotenv requires an object, got string",
+		},
+		{
+			name:      "unknown format",
+			value:     object,
+			format:    "TOML",
+			expectErr: "unsupported format TOML",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Format(tc.value, tc.format)
+			if tc.expectErr != "" {
+				require.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, result)
+		})
+	}
+}
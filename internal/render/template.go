@@ -0,0 +1,187 @@
+package render
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+const (
+	// maxOutputSize is the size limit of a ConfigMap or Secret, no template output can be larger
+	maxOutputSize = 1 << 20
+	// maxSteps bounds the range iterations and template calls of an execution
+	maxSteps = 100000
+	// timeout bounds the duration of an execution
+	timeout = time.Second
+	// stepFunc is called on every range iteration and template call to enforce the limits
+	stepFunc = "_step"
+)
+
+// errOutputTooLarge is returned when a template renders more than maxOutputSize bytes
+var errOutputTooLarge = fmt.Errorf("template output exceeds %d bytes", maxOutputSize)
+
+// funcs is the function set of output templates in addition to the text/template builtins
+// such as urlquery, printf or index. It is a small, side effect free subset of sprig.
+var funcs = template.FuncMap{
+	"b64enc":     b64enc,
+	"b64dec":     b64dec,
+	"default":    defaultValue,
+	"join":       join,
+	"lower":      strings.ToLower,
+	"quote":      strconv.Quote,
+	"replace":    replace,
+	"toJson":     toJSON,
+	"trim":       strings.TrimSpace,
+	"trimPrefix": trimPrefix,
+	"trimSuffix": trimSuffix,
+	"upper":      strings.ToUpper,
+	// replaced by the step of the execution budget when a template is executed
+	stepFunc: func() string { return "" },
+}
+
+// ParseTemplate parses an output template. Referencing a field missing in the input fails the
+// execution, optional fields can be read with index, e.g. `{{ index .status "port" | default "5432" }}`.
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("output").Option("missingkey=error").Funcs(funcs).Parse(text)
+}
+
+// Template renders the output template text against the input. The execution is stopped when it
+// exceeds maxSteps range iterations or template calls, maxOutputSize bytes or the timeout, so a
+// template like `{{ range 1000000000 }}{{ end }}` can't pin the reconciler.
+func Template(ctx context.Context, text string, input map[string]any) (string, error) {
+	tmpl, err := ParseTemplate(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+	step, err := ParseTemplate("{{ " + stepFunc + " }}")
+	if err != nil {
+		return "", err
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			node := step.Tree.Root.Nodes[0]
+			instrument(t.Tree.Root, node)
+			t.Tree.Root.Nodes = append([]parse.Node{node}, t.Tree.Root.Nodes...)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	b := &budget{ctx: ctx}
+	tmpl.Funcs(template.FuncMap{stepFunc: b.step})
+	out := &limitedWriter{limit: maxOutputSize}
+	if err := tmpl.Execute(out, input); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// instrument prepends the step to the body of every range within the list.
+func instrument(list *parse.ListNode, step parse.Node) {
+	if list == nil {
+		return
+	}
+	for _, n := range list.Nodes {
+		switch n := n.(type) {
+		case *parse.IfNode:
+			instrument(n.List, step)
+			instrument(n.ElseList, step)
+		case *parse.WithNode:
+			instrument(n.List, step)
+			instrument(n.ElseList, step)
+		case *parse.RangeNode:
+			instrument(n.List, step)
+			instrument(n.ElseList, step)
+			n.List.Nodes = append([]parse.Node{step}, n.List.Nodes...)
+		}
+	}
+}
+
+// budget counts the steps of an execution.
+type budget struct {
+	ctx   context.Context
+	steps int
+}
+
+func (b *budget) step() (string, error) {
+	b.steps++
+	if b.steps > maxSteps {
+		return "", fmt.Errorf("template exceeds %d iterations", maxSteps)
+	}
+	if err := b.ctx.Err(); err != nil {
+		return "", fmt.Errorf("template execution stopped: %w", err)
+	}
+	return "", nil
+}
+
+// limitedWriter fails writes beyond its limit, which aborts the execution.
+type limitedWriter struct {
+	strings.Builder
+	limit int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.Len()+len(p) > w.limit {
+		return 0, errOutputTooLarge
+	}
+	return w.Builder.Write(p)
+}
+
+func b64enc(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func b64dec(s string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
+
+// defaultValue returns def if value is empty, arguments are in sprig order to allow piping.
+func defaultValue(def any, value any) any {
+	if value == nil {
+		return def
+	}
+	v := reflect.ValueOf(value)
+	if v.IsZero() || (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0 {
+		return def
+	}
+	return value
+}
+
+func join(sep string, values []any) string {
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		parts = append(parts, fmt.Sprint(v))
+	}
+	return strings.Join(parts, sep)
+}
+
+func replace(old, replacement, s string) string {
+	return strings.ReplaceAll(s, old, replacement)
+}
+
+func toJSON(value any) (string, error) {
+	serialized, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(serialized), nil
+}
+
+func trimPrefix(prefix, s string) string {
+	return strings.TrimPrefix(s, prefix)
+}
+
+func trimSuffix(suffix, s string) string {
+	return strings.TrimSuffix(s, suffix)
+}
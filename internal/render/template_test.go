@@ -0,0 +1,114 @@
+package render
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplate(t *testing.T) {
+	input := map[string]any{
+		"db": map[string]any{
+			"status": map[string]any{"host": "10.0.0.1", "port": int64(5432)},
+		},
+		"user": map[string]any{
+			"spec": map[string]any{"name": "app user", "password": "p@ss:word"},
+		},
+		"hosts": []any{"a", "b"},
+	}
+	for _, tc := range []struct {
+		name      string
+		template  string
+		expected  string
+		expectErr string
+	}{
+		{
+			name:     "composite url",
+			template: `postgres://{{ .user.spec.name | urlquery }}@{{ .db.status.host }}:{{ .db.status.port }}/app`,
+			expected: "postgres://app+user@10.0.0.1:5432/app",
+		},
+		{
+			name:     "base64",
+			template: `{{ .user.spec.password | b64enc }}`,
+			expected: "cEBzczp3b3Jk",
+		},
+		{
+			name:     "default for missing field",
+			template: `{{ index .db.status "replicaHost" | default .db.status.host }}`,
+			expected: "10.0.0.1",
+		},
+		{
+			name:     "string functions",
+			template: `{{ .user.spec.name | upper | replace " " "_" | quote }}`,
+			expected: `"APP_USER"`,
+		},
+		{
+			name:     "join",
+			template: `{{ join "," .hosts }}`,
+			expected: "a,b",
+		},
+		{
+			name:     "json",
+			template: `{{ toJson .db.status }}`,
+			expected: `{"host":"10.0.0.1","port":5432}`,
+		},
+		{
+			name:      "missing field",
+			template:  `{{ .db.status.hostt }}`,
+			expectErr: `map has no entry for key "hostt"`,
+		},
+		{
+			name:      "invalid template",
+			template:  `{{ .db.status.host`,
+			expectErr: "invalid template",
+		},
+		{
+			name:     "range",
+			template: `{{ range $i, $h := .hosts }}{{ if $i }},{{ end }}{{ $h }}{{ end }}`,
+			expected: "a,b",
+		},
+		{
+			name:      "unbounded range",
+			template:  `{{ range 1000000000 }}{{ end }}`,
+			expectErr: "template exceeds 100000 iterations",
+		},
+		{
+			name:      "nested ranges",
+			template:  `{{ range 1000 }}{{ range 1000 }}{{ end }}{{ end }}`,
+			expectErr: "template exceeds 100000 iterations",
+		},
+		{
+			name:      "recursive template",
+			template:  `{{ define "r" }}{{ template "r" . }}{{ template "r" . }}{{ end }}{{ template "r" . }}`,
+			expectErr: "template exceeds 100000 iterations",
+		},
+		{
+			name:      "output too large",
+			template:  `{{ range 300 }}{{ printf "%5000s" "" }}{{ end }}`,
+			expectErr: "template output exceeds 1048576 bytes",
+		},
+		{
+			name:      "unknown function",
+			template:  `{{ .db.status.host | exec }}`,
+			expectErr: `function "exec" not defined`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Template(context.Background(), tc.template, input)
+			if tc.expectErr != "" {
+				require.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestTemplateCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Template(ctx, `{{ range 10 }}{{ end }}`, nil)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,104 @@
+package resourcemanager
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Refresh re-runs the discovery of the allowed groups. It returns the kinds that became supported
+// and those that are no longer supported, registered kinds stay supported either way. Kinds of
+// groups whose discovery failed are kept until it succeeds again.
+func (r *ResourceManager) Refresh() (added, removed []schema.GroupVersionKind, err error) {
+	preferredResources, err := r.client.ServerPreferredResources()
+	failed := make(map[schema.GroupVersion]bool)
+	if err != nil {
+		var groupErr *discovery.ErrGroupDiscoveryFailed
+		if !errors.As(err, &groupErr) {
+			return nil, nil, err
+		}
+		for gv := range groupErr.Groups {
+			failed[gv] = true
+		}
+	}
+	resources, parseErr := allowedResources(preferredResources, r.groups)
+	if parseErr != nil {
+		return nil, nil, parseErr
+	}
+
+	r.resourcesMu.Lock()
+	defer r.resourcesMu.Unlock()
+	for gvk, plural := range r.supportedResources {
+		if failed[gvk.GroupVersion()] {
+			resources[gvk] = plural
+		}
+	}
+	for gvk := range resources {
+		if _, ok := r.supportedResources[gvk]; ok {
+			continue
+		}
+		if _, ok := r.registered(gvk); !ok {
+			added = append(added, gvk)
+		}
+	}
+	for gvk := range r.supportedResources {
+		if _, ok := resources[gvk]; ok {
+			continue
+		}
+		if _, ok := r.registered(gvk); !ok {
+			removed = append(removed, gvk)
+		}
+	}
+	r.supportedResources = resources
+	r.updateMetricsLocked()
+	// a partial discovery is still applied, the error reports the groups that failed
+	return added, removed, err
+}
+
+// Rediscovery re-runs the discovery periodically, so kinds installed or removed after the manager
+// started are supported and watched, or no longer, without a restart.
+type Rediscovery struct {
+	Manager  *ResourceManager
+	Watches  Watches
+	Interval time.Duration
+}
+
+// Start implements manager.Runnable.
+func (d *Rediscovery) Start(ctx context.Context) error {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			d.refresh(ctx)
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, the webhook of every replica
+// validates against the discovered kinds.
+func (d *Rediscovery) NeedLeaderElection() bool {
+	return false
+}
+
+func (d *Rediscovery) refresh(ctx context.Context) {
+	logger := log.FromContext(ctx).WithName("rediscovery")
+	added, removed, err := d.Manager.Refresh()
+	if err != nil {
+		discoveryFailures.Inc()
+		logger.Error(err, "failed to discover resources")
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+	logger.Info("supported resources changed", "added", added, "removed", removed)
+	if err := UpdateWatches(ctx, d.Watches, added, removed); err != nil {
+		logger.Error(err, "failed to update watches")
+	}
+}
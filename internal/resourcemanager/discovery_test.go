@@ -0,0 +1,87 @@
+package resourcemanager
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestRMRefresh(t *testing.T) {
+	redisGVK := schema.GroupVersionKind{Group: "redis.cnrm.cloud.google.com", Version: "v1beta1", Kind: "RedisInstance"}
+	sqlGVK := schema.GroupVersionKind{Group: "sql.cnrm.cloud.google.com", Version: "v1beta1", Kind: "SQLInstance"}
+	dbInstanceGVK := schema.GroupVersionKind{Group: "rds.services.k8s.aws", Version: "v1alpha1", Kind: "DBInstance"}
+	certGVK := schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+	client := &testPreferredResources{gvks: []schema.GroupVersionKind{redisGVK, sqlGVK}}
+	rm, err := NewResourceManager(client, DefaultGroups)
+	require.NoError(t, err)
+
+	// the ACK RDS controller is installed after the start
+	client.gvks = append(client.gvks, dbInstanceGVK, certGVK)
+	added, removed, err := rm.Refresh()
+	require.NoError(t, err)
+	require.Equal(t, []schema.GroupVersionKind{dbInstanceGVK}, added)
+	require.Empty(t, removed)
+	require.NoError(t, rm.Validate("rds.services.k8s.aws/v1alpha1", "DBInstance"))
+
+	// a failed group keeps its kinds until its discovery succeeds again
+	client.failed = []schema.GroupVersion{redisGVK.GroupVersion()}
+	added, removed, err = rm.Refresh()
+	require.ErrorContains(t, err, "unable to retrieve the complete list of server APIs")
+	require.Empty(t, added)
+	require.Empty(t, removed)
+	require.NoError(t, rm.Validate("redis.cnrm.cloud.google.com/v1beta1", "RedisInstance"))
+
+	// kinds registered with an ExportableResource stay watched when their CRD goes away
+	rm.Register("sql", map[schema.GroupVersionKind]string{sqlGVK: "sqlinstances"})
+	client.failed = nil
+	client.gvks = []schema.GroupVersionKind{certGVK}
+	added, removed, err = rm.Refresh()
+	require.NoError(t, err)
+	require.Empty(t, added)
+	require.ElementsMatch(t, []schema.GroupVersionKind{redisGVK, dbInstanceGVK}, removed)
+	require.ElementsMatch(t, []schema.GroupVersionKind{sqlGVK}, rm.Resources())
+}
+
+func TestRediscovery(t *testing.T) {
+	redisGVK := schema.GroupVersionKind{Group: "redis.cnrm.cloud.google.com", Version: "v1beta1", Kind: "RedisInstance"}
+	dbInstanceGVK := schema.GroupVersionKind{Group: "rds.services.k8s.aws", Version: "v1alpha1", Kind: "DBInstance"}
+	client := &testPreferredResources{gvks: []schema.GroupVersionKind{redisGVK}}
+	rm, err := NewResourceManager(client, DefaultGroups)
+	require.NoError(t, err)
+	watches := &testWatches{}
+	rediscovery := &Rediscovery{Manager: rm, Watches: watches}
+
+	client.gvks = []schema.GroupVersionKind{dbInstanceGVK}
+	rediscovery.refresh(context.Background())
+	require.Equal(t, []schema.GroupVersionKind{dbInstanceGVK}, watches.started)
+	require.Equal(t, []schema.GroupVersionKind{redisGVK}, watches.stopped)
+
+	require.NoError(t, testutil.CollectAndCompare(supportedKinds, strings.NewReader(`
+# HELP field_exporter_supported_kinds Kinds exports can read from, by origin: discovery in the allowed groups or registration with an ExportableResource.
+# TYPE field_exporter_supported_kinds gauge
+field_exporter_supported_kinds{group="rds.services.k8s.aws",kind="DBInstance",origin="discovery",version="v1alpha1"} 1
+`)))
+
+	failures := testutil.ToFloat64(discoveryFailures)
+	client.failed = []schema.GroupVersion{dbInstanceGVK.GroupVersion()}
+	rediscovery.refresh(context.Background())
+	require.Equal(t, failures+1, testutil.ToFloat64(discoveryFailures))
+}
+
+type testWatches struct {
+	started, stopped []schema.GroupVersionKind
+}
+
+func (w *testWatches) StartWatch(_ context.Context, gvk schema.GroupVersionKind) error {
+	w.started = append(w.started, gvk)
+	return nil
+}
+
+func (w *testWatches) StopWatch(_ context.Context, gvk schema.GroupVersionKind) error {
+	w.stopped = append(w.stopped, gvk)
+	return nil
+}
@@ -0,0 +1,91 @@
+package resourcemanager
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// ConfigConnectorGroups are the API groups of Google Cloud Config Connector resources
+var ConfigConnectorGroups = Groups{
+	"alloydb.cnrm.cloud.google.com",
+	"iam.cnrm.cloud.google.com",
+	"redis.cnrm.cloud.google.com",
+	"sql.cnrm.cloud.google.com",
+	"storage.cnrm.cloud.google.com",
+}
+
+// ACKGroups are the API groups of AWS Controllers for Kubernetes resources
+var ACKGroups = Groups{
+	"rds.services.k8s.aws",
+	"elasticache.services.k8s.aws",
+	"dynamodb.services.k8s.aws",
+}
+
+// ASOGroups are the API groups of Azure Service Operator v2 resources
+var ASOGroups = Groups{
+	"cache.azure.com",
+	"dbformysql.azure.com",
+	"dbforpostgresql.azure.com",
+	"documentdb.azure.com",
+	"storage.azure.com",
+}
+
+// CrossplaneGroups are the API groups of the managed resources of Crossplane providers, e.g.
+// s3.aws.upbound.io or ec2.aws.crossplane.io. The groups of Crossplane itself, e.g.
+// apiextensions.crossplane.io, and of the provider configs, e.g. aws.upbound.io, don't match.
+var CrossplaneGroups = Groups{
+	"*.*.upbound.io",
+	"*.*.crossplane.io",
+}
+
+// DefaultGroups are the API groups exports can read from unless configured otherwise
+var DefaultGroups = slices.Concat(ConfigConnectorGroups, ACKGroups, ASOGroups, CrossplaneGroups)
+
+// Groups is the allowlist of API groups exports can read from. Every entry is a glob pattern
+// matched against the whole group, e.g. *.cnrm.cloud.google.com. It implements flag.Value so
+// the manager and the CLI share the same configuration.
+type Groups []string
+
+// ParseGroups parses a comma separated list of group patterns.
+func ParseGroups(value string) (Groups, error) {
+	var groups Groups
+	for _, pattern := range strings.Split(value, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid group pattern %q: %w", pattern, err)
+		}
+		groups = append(groups, pattern)
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("no group patterns in %q", value)
+	}
+	return groups, nil
+}
+
+// Allows reports whether the group matches any of the patterns.
+func (g Groups) Allows(group string) bool {
+	for _, pattern := range g {
+		if ok, _ := path.Match(pattern, group); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Groups) String() string {
+	return strings.Join(*g, ",")
+}
+
+func (g *Groups) Set(value string) error {
+	groups, err := ParseGroups(value)
+	if err != nil {
+		return err
+	}
+	*g = groups
+	return nil
+}
@@ -0,0 +1,72 @@
+package resourcemanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGroups(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		value     string
+		expected  Groups
+		expectErr string
+	}{
+		{
+			name:     "groups and patterns",
+			value:    "*.cnrm.cloud.google.com, s3.services.k8s.aws,",
+			expected: Groups{"*.cnrm.cloud.google.com", "s3.services.k8s.aws"},
+		},
+		{
+			name:      "invalid pattern",
+			value:     "[a-.services.k8s.aws",
+			expectErr: `invalid group pattern "[a-.services.k8s.aws": syntax error in pattern`,
+		},
+		{
+			name:      "empty",
+			value:     " , ",
+			expectErr: `no group patterns in " , "`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			groups, err := ParseGroups(tc.value)
+			if tc.expectErr != "" {
+				require.EqualError(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, groups)
+		})
+	}
+}
+
+func TestGroupsAllows(t *testing.T) {
+	groups := Groups{"*.cnrm.cloud.google.com", "rds.services.k8s.aws", "*.upbound.io"}
+	for group, allowed := range map[string]bool{
+		"redis.cnrm.cloud.google.com":   true,
+		"compute.cnrm.cloud.google.com": true,
+		"cnrm.cloud.google.com":         false,
+		"rds.services.k8s.aws":          true,
+		"s3.services.k8s.aws":           false,
+		"rds.aws.upbound.io":            true,
+		"":                              false,
+	} {
+		require.Equal(t, allowed, groups.Allows(group), group)
+	}
+}
+
+func TestCrossplaneGroups(t *testing.T) {
+	for group, allowed := range map[string]bool{
+		"s3.aws.upbound.io":           true,
+		"rds.aws.upbound.io":          true,
+		"ec2.aws.crossplane.io":       true,
+		"aws.upbound.io":              false,
+		"apiextensions.crossplane.io": false,
+		"pkg.crossplane.io":           false,
+		"secrets.crossplane.io":       false,
+		"platform.example.org":        false,
+	} {
+		require.Equal(t, allowed, CrossplaneGroups.Allows(group), group)
+	}
+}
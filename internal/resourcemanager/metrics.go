@@ -0,0 +1,46 @@
+package resourcemanager
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// origins of a supported kind
+const (
+	originDiscovery    = "discovery"
+	originRegistration = "registration"
+)
+
+var (
+	supportedKinds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "field_exporter_supported_kinds",
+		Help: "Kinds exports can read from, by origin: discovery in the allowed groups or registration with an ExportableResource.",
+	}, []string{"group", "version", "kind", "origin"})
+	discoveryFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "field_exporter_discovery_failures_total",
+		Help: "Total number of periodic discoveries of the allowed groups that failed, at least partially.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(supportedKinds, discoveryFailures)
+}
+
+func (r *ResourceManager) updateMetrics() {
+	r.resourcesMu.RLock()
+	defer r.resourcesMu.RUnlock()
+	r.updateMetricsLocked()
+}
+
+// updateMetricsLocked sets the supported kinds, resourcesMu must be held.
+func (r *ResourceManager) updateMetricsLocked() {
+	supportedKinds.Reset()
+	for gvk := range r.supportedResources {
+		supportedKinds.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind, originDiscovery).Set(1)
+	}
+	for _, resources := range r.registrations {
+		for gvk := range resources {
+			supportedKinds.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind, originRegistration).Set(1)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package resourcemanager
+
+import (
+	"fmt"
+	"maps"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// SourceAllowlist decides which kinds exports can read from, it is implemented by Groups and by
+// the ResourceManager, which also allows registered kinds.
+type SourceAllowlist interface {
+	// CheckKind returns an error when exports can't read from the kind
+	CheckKind(gvk schema.GroupVersionKind) error
+}
+
+// CheckKind returns an error when the group of the kind isn't allowed.
+func (g Groups) CheckKind(gvk schema.GroupVersionKind) error {
+	if !g.Allows(gvk.Group) {
+		return fmt.Errorf("group must match one of %v", g)
+	}
+	return nil
+}
+
+// CheckKind returns an error when the kind is neither registered nor in an allowed group.
+func (r *ResourceManager) CheckKind(gvk schema.GroupVersionKind) error {
+	r.resourcesMu.RLock()
+	defer r.resourcesMu.RUnlock()
+	if _, ok := r.registered(gvk); ok {
+		return nil
+	}
+	return r.groups.CheckKind(gvk)
+}
+
+// Register replaces the kinds registered under name, e.g. by an ExportableResource, resources maps
+// every kind to its plural resource name. It returns the kinds that became supported and those
+// that are no longer supported, watches on them have to be started and stopped.
+func (r *ResourceManager) Register(name string, resources map[schema.GroupVersionKind]string) (added, removed []schema.GroupVersionKind) {
+	r.resourcesMu.Lock()
+	defer r.resourcesMu.Unlock()
+	previous := r.registrations[name]
+	if len(resources) == 0 {
+		delete(r.registrations, name)
+	} else {
+		r.registrations[name] = maps.Clone(resources)
+	}
+	for gvk := range resources {
+		if _, ok := previous[gvk]; ok || r.discovered(gvk) || r.registeredElsewhere(name, gvk) {
+			continue
+		}
+		added = append(added, gvk)
+	}
+	for gvk := range previous {
+		if _, ok := resources[gvk]; ok || r.discovered(gvk) || r.registeredElsewhere(name, gvk) {
+			continue
+		}
+		removed = append(removed, gvk)
+	}
+	r.updateMetricsLocked()
+	return added, removed
+}
+
+// Unregister removes the kinds registered under name, it returns those that are no longer supported.
+func (r *ResourceManager) Unregister(name string) []schema.GroupVersionKind {
+	_, removed := r.Register(name, nil)
+	return removed
+}
+
+// discovered reports whether the kind was discovered in the allowed groups.
+func (r *ResourceManager) discovered(gvk schema.GroupVersionKind) bool {
+	_, ok := r.supportedResources[gvk]
+	return ok
+}
+
+// registered returns the plural resource name of a registered kind.
+func (r *ResourceManager) registered(gvk schema.GroupVersionKind) (string, bool) {
+	for _, resources := range r.registrations {
+		if plural, ok := resources[gvk]; ok {
+			return plural, true
+		}
+	}
+	return "", false
+}
+
+func (r *ResourceManager) registeredElsewhere(name string, gvk schema.GroupVersionKind) bool {
+	for other, resources := range r.registrations {
+		if _, ok := resources[gvk]; ok && other != name {
+			return true
+		}
+	}
+	return false
+}
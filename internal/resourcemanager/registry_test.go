@@ -0,0 +1,63 @@
+package resourcemanager
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestRMRegister(t *testing.T) {
+	redisGVK := schema.GroupVersionKind{Group: "redis.cnrm.cloud.google.com", Version: "v1beta1", Kind: "RedisInstance"}
+	certV1 := schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+	certV1beta1 := schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1beta1", Kind: "Certificate"}
+	kafkaUserGVK := schema.GroupVersionKind{Group: "kafka.strimzi.io", Version: "v1beta2", Kind: "KafkaUser"}
+	rm, err := NewResourceManager(&testPreferredResources{gvks: []schema.GroupVersionKind{redisGVK}}, DefaultGroups)
+	require.NoError(t, err)
+
+	require.EqualError(t, rm.Validate("cert-manager.io/v1", "Certificate"),
+		"unsupported GroupVersion cert-manager.io/v1, group must match one of "+fmt.Sprint(DefaultGroups))
+	require.Error(t, rm.CheckKind(certV1))
+
+	added, removed := rm.Register("certificates", map[schema.GroupVersionKind]string{certV1: "certificates"})
+	require.Equal(t, []schema.GroupVersionKind{certV1}, added)
+	require.Empty(t, removed)
+	require.NoError(t, rm.Validate("cert-manager.io/v1", "Certificate"))
+	require.NoError(t, rm.CheckKind(certV1))
+	require.EqualError(t, rm.Validate("cert-manager.io/v1", "Issuer"),
+		"unsupported GroupVersion cert-manager.io/v1, group must match one of "+fmt.Sprint(DefaultGroups))
+	require.ElementsMatch(t, []schema.GroupVersionKind{redisGVK, certV1}, rm.Resources())
+
+	// a registration of a discovered kind doesn't add a watch and doesn't remove it when unregistered
+	added, removed = rm.Register("redis", map[schema.GroupVersionKind]string{redisGVK: "redisinstances"})
+	require.Empty(t, added)
+	require.Empty(t, removed)
+	require.Empty(t, rm.Unregister("redis"))
+
+	// replacing the versions of a registration
+	added, removed = rm.Register("certificates", map[schema.GroupVersionKind]string{certV1beta1: "certificates"})
+	require.Equal(t, []schema.GroupVersionKind{certV1beta1}, added)
+	require.Equal(t, []schema.GroupVersionKind{certV1}, removed)
+	require.Error(t, rm.CheckKind(certV1))
+
+	// a kind registered twice stays supported until both registrations are gone
+	added, _ = rm.Register("certificates-copy", map[schema.GroupVersionKind]string{certV1beta1: "certificates"})
+	require.Empty(t, added)
+	require.Empty(t, rm.Unregister("certificates"))
+	require.NoError(t, rm.CheckKind(certV1beta1))
+	require.Equal(t, []schema.GroupVersionKind{certV1beta1}, rm.Unregister("certificates-copy"))
+	require.Error(t, rm.CheckKind(certV1beta1))
+
+	require.Empty(t, rm.Unregister("unknown"))
+	added, _ = rm.Register("kafka-users", map[schema.GroupVersionKind]string{kafkaUserGVK: "kafkausers"})
+	require.Equal(t, []schema.GroupVersionKind{kafkaUserGVK}, added)
+	require.ElementsMatch(t, []schema.GroupVersionKind{redisGVK, kafkaUserGVK}, rm.Resources())
+}
+
+func TestGroupsCheckKind(t *testing.T) {
+	groups := Groups{"*.cnrm.cloud.google.com"}
+	require.NoError(t, groups.CheckKind(schema.GroupVersionKind{Group: "sql.cnrm.cloud.google.com", Version: "v1beta1", Kind: "SQLInstance"}))
+	require.EqualError(t, groups.CheckKind(schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}),
+		"group must match one of [*.cnrm.cloud.google.com]")
+}
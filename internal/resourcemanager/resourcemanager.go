@@ -2,39 +2,48 @@ package resourcemanager
 
 import (
 	"fmt"
-	"sort"
+	"slices"
+	"strings"
+	"sync"
 
+	"golang.org/x/sync/singleflight"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-var (
-	supportedAPIGroups = map[string]struct{}{
-		"alloydb.cnrm.cloud.google.com": {},
-		"iam.cnrm.cloud.google.com":     {},
-		"redis.cnrm.cloud.google.com":   {},
-		"sql.cnrm.cloud.google.com":     {},
-		"storage.cnrm.cloud.google.com": {},
-		"rds.services.k8s.aws":          {},
-		"elasticache.services.k8s.aws":  {},
-		"dynamodb.services.k8s.aws":     {},
-	}
-)
-
 type PreferredResources interface {
 	ServerPreferredResources() ([]*metav1.APIResourceList, error)
 }
 
-func NewResourceManager(client PreferredResources) (*ResourceManager, error) {
-	resources, err := supportedResources(client)
+func NewResourceManager(client PreferredResources, groups Groups) (*ResourceManager, error) {
+	resources, err := supportedResources(client, groups)
 	if err != nil {
 		return nil, err
 	}
-	return &ResourceManager{supportedResources: resources}, nil
+	r := &ResourceManager{
+		client:             client,
+		groups:             groups,
+		supportedResources: resources,
+		registrations:      make(map[string]map[schema.GroupVersionKind]string),
+	}
+	r.updateMetrics()
+	return r, nil
 }
 
 type ResourceManager struct {
-	supportedResources map[schema.GroupVersionKind]struct{}
+	client PreferredResources
+	groups Groups
+
+	resourcesMu sync.RWMutex
+	// supportedResources maps every supported kind to its plural resource name
+	supportedResources map[schema.GroupVersionKind]string
+	// registrations maps the name of every ExportableResource to the kinds it registers
+	registrations map[string]map[schema.GroupVersionKind]string
+
+	crds    CRDGetter
+	mu      sync.Mutex
+	schemas map[schema.GroupVersionKind]cachedSchema
+	fetches singleflight.Group
 }
 
 func (r *ResourceManager) Validate(apiVersion, kind string) error {
@@ -43,53 +52,99 @@ func (r *ResourceManager) Validate(apiVersion, kind string) error {
 		return err
 	}
 
-	if _, ok := supportedAPIGroups[gv.Group]; !ok {
-		supportedSuffixes := []string{"cnrm.cloud.google.com", "services.k8s.aws"}
-		sort.Strings(supportedSuffixes)
-		return fmt.Errorf("unsupported GroupVersion %s, group must have suffix in %v", gv, supportedSuffixes)
-	}
-
 	gvk := schema.GroupVersionKind{
 		Group:   gv.Group,
 		Version: gv.Version,
 		Kind:    kind,
 	}
 
+	r.resourcesMu.RLock()
+	defer r.resourcesMu.RUnlock()
+	if _, ok := r.registered(gvk); ok {
+		return nil
+	}
+
+	if !r.groups.Allows(gv.Group) {
+		return fmt.Errorf("unsupported GroupVersion %s, group must match one of %v", gv, r.groups)
+	}
+
 	if _, ok := r.supportedResources[gvk]; !ok {
 		return fmt.Errorf("unsupported resource: %s", gvk)
 	}
 	return nil
 }
 
-func supportedResources(client PreferredResources) (map[schema.GroupVersionKind]struct{}, error) {
+func supportedResources(client PreferredResources, groups Groups) (map[schema.GroupVersionKind]string, error) {
 	preferredResources, err := client.ServerPreferredResources()
 	if err != nil {
 		return nil, err
 	}
-	output := make(map[schema.GroupVersionKind]struct{})
+	return allowedResources(preferredResources, groups)
+}
+
+// allowedResources maps the kinds of the allowed groups to their plural resource names.
+func allowedResources(preferredResources []*metav1.APIResourceList, groups Groups) (map[schema.GroupVersionKind]string, error) {
+	output := make(map[schema.GroupVersionKind]string)
 	for _, resourceList := range preferredResources {
 		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
 		if err != nil {
 			return nil, err
 		}
-		if _, ok := supportedAPIGroups[gv.Group]; !ok {
+		if !groups.Allows(gv.Group) {
 			continue
 		}
 		for _, r := range resourceList.APIResources {
+			// subresources like redisinstances/status share the kind of their resource
+			if strings.Contains(r.Name, "/") {
+				continue
+			}
 			output[schema.GroupVersionKind{
 				Group:   gv.Group,
 				Version: gv.Version,
 				Kind:    r.Kind,
-			}] = struct{}{}
+			}] = r.Name
 		}
 	}
 	return output, nil
 }
 
+// Resources returns the supported kinds, discovered in the allowed groups or registered.
 func (r *ResourceManager) Resources() []schema.GroupVersionKind {
+	r.resourcesMu.RLock()
+	defer r.resourcesMu.RUnlock()
 	output := make([]schema.GroupVersionKind, 0, len(r.supportedResources))
 	for gvk := range r.supportedResources {
 		output = append(output, gvk)
 	}
+	for _, resources := range r.registrations {
+		for gvk := range resources {
+			if _, ok := r.supportedResources[gvk]; ok || slices.Contains(output, gvk) {
+				continue
+			}
+			output = append(output, gvk)
+		}
+	}
 	return output
 }
+
+// Supports reports whether exports can read from the kind, it was discovered in the allowed
+// groups or registered.
+func (r *ResourceManager) Supports(gvk schema.GroupVersionKind) bool {
+	_, ok := r.resource(gvk)
+	return ok
+}
+
+// resource returns the plural resource name of a supported kind.
+func (r *ResourceManager) resource(gvk schema.GroupVersionKind) (string, bool) {
+	r.resourcesMu.RLock()
+	defer r.resourcesMu.RUnlock()
+	if plural, ok := r.supportedResources[gvk]; ok {
+		return plural, true
+	}
+	return r.registered(gvk)
+}
+
+// Groups returns the allowlist of API groups.
+func (r *ResourceManager) Groups() Groups {
+	return r.groups
+}
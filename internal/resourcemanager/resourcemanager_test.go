@@ -1,11 +1,15 @@
 package resourcemanager
 
 import (
+	"errors"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
 )
 
 func TestRMValidate(t *testing.T) {
@@ -25,13 +29,13 @@ func TestRMValidate(t *testing.T) {
 			name:       "valid resource, invalid group",
 			apiVersion: "v1",
 			kind:       "Secret",
-			expectErr:  "unsupported GroupVersion v1, group must have suffix in [cnrm.cloud.google.com services.k8s.aws]",
+			expectErr:  "unsupported GroupVersion v1, group must match one of [alloydb.cnrm.cloud.google.com",
 		},
 		{
 			name:       "invalid resource, invalid group",
 			apiVersion: "v1",
 			kind:       "RedisCluster",
-			expectErr:  "unsupported GroupVersion v1, group must have suffix in [cnrm.cloud.google.com services.k8s.aws]",
+			expectErr:  "unsupported GroupVersion v1, group must match one of [alloydb.cnrm.cloud.google.com",
 		},
 		{
 			name:       "invalid resource, valid group",
@@ -57,7 +61,7 @@ func TestRMValidate(t *testing.T) {
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			rm, err := NewResourceManager(&testPreferredResources{gvks: registeredGVKs})
+			rm, err := NewResourceManager(&testPreferredResources{gvks: registeredGVKs}, DefaultGroups)
 			require.NoError(t, err)
 			err = rm.Validate(tc.apiVersion, tc.kind)
 			if tc.expectErr == "" {
@@ -71,18 +75,31 @@ func TestRMValidate(t *testing.T) {
 
 type testPreferredResources struct {
 	gvks []schema.GroupVersionKind
+	// failed are the group versions whose discovery fails, they are left out of the lists
+	failed []schema.GroupVersion
 }
 
 func (r *testPreferredResources) ServerPreferredResources() ([]*metav1.APIResourceList, error) {
 	apiResources := make([]*metav1.APIResourceList, 0, len(r.gvks))
 	for _, gvk := range r.gvks {
+		if slices.Contains(r.failed, gvk.GroupVersion()) {
+			continue
+		}
 		apiResources = append(apiResources, &metav1.APIResourceList{
 			GroupVersion: gvk.GroupVersion().Identifier(),
 			APIResources: []metav1.APIResource{
-				{Kind: gvk.Kind},
+				{Name: strings.ToLower(gvk.Kind) + "s", Kind: gvk.Kind},
+				{Name: strings.ToLower(gvk.Kind) + "s/status", Kind: gvk.Kind},
 			},
 		})
 	}
+	if len(r.failed) > 0 {
+		err := &discovery.ErrGroupDiscoveryFailed{Groups: make(map[schema.GroupVersion]error)}
+		for _, gv := range r.failed {
+			err.Groups[gv] = errors.New("the server is currently unable to handle the request")
+		}
+		return apiResources, err
+	}
 	return apiResources, nil
 }
 
@@ -135,10 +152,23 @@ func TestRMResources(t *testing.T) {
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			rm, err := NewResourceManager(&testPreferredResources{gvks: tc.registeredGVKs})
+			rm, err := NewResourceManager(&testPreferredResources{gvks: tc.registeredGVKs}, DefaultGroups)
 			require.NoError(t, err)
 			gvks := rm.Resources()
 			require.ElementsMatch(t, tc.expectedGVKs, gvks)
 		})
 	}
 }
+
+func TestRMConfiguredGroups(t *testing.T) {
+	computeGVK := schema.GroupVersionKind{Group: "compute.cnrm.cloud.google.com", Version: "v1beta1", Kind: "ComputeAddress"}
+	redisGVK := schema.GroupVersionKind{Group: "redis.cnrm.cloud.google.com", Version: "v1beta1", Kind: "RedisInstance"}
+	bucketGVK := schema.GroupVersionKind{Group: "s3.services.k8s.aws", Version: "v1alpha1", Kind: "Bucket"}
+	rm, err := NewResourceManager(&testPreferredResources{gvks: []schema.GroupVersionKind{computeGVK, redisGVK, bucketGVK}},
+		Groups{"*.cnrm.cloud.google.com"})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []schema.GroupVersionKind{computeGVK, redisGVK}, rm.Resources())
+	require.NoError(t, rm.Validate("compute.cnrm.cloud.google.com/v1beta1", "ComputeAddress"))
+	require.EqualError(t, rm.Validate("s3.services.k8s.aws/v1alpha1", "Bucket"),
+		"unsupported GroupVersion s3.services.k8s.aws/v1alpha1, group must match one of [*.cnrm.cloud.google.com]")
+}
@@ -0,0 +1,288 @@
+package resourcemanager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// schemaCacheTTL bounds how long a fetched schema is used, CRDs change when their operator is upgraded
+const schemaCacheTTL = 10 * time.Minute
+
+// schemaFetchTimeout bounds fetching a CRD, lookups waiting for it give up with their own context
+const schemaFetchTimeout = 10 * time.Second
+
+// IndexStep is the step of a field path that indexes or iterates an array
+const IndexStep = "[]"
+
+// CRDGetter fetches CustomResourceDefinitions, it is implemented by the apiextensions clientset.
+type CRDGetter interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*apiextensionsv1.CustomResourceDefinition, error)
+}
+
+type cachedSchema struct {
+	schema  *apiextensionsv1.JSONSchemaProps
+	fetched time.Time
+}
+
+// WithSchemas enables fetching the OpenAPI v3 schemas of the supported kinds from their CRDs.
+func (r *ResourceManager) WithSchemas(crds CRDGetter) *ResourceManager {
+	r.crds = crds
+	r.schemas = make(map[schema.GroupVersionKind]cachedSchema)
+	return r
+}
+
+// Schema returns the cached OpenAPI v3 schema of a supported kind. It returns nil when schemas are
+// not enabled or the CRD doesn't define a schema for the version.
+func (r *ResourceManager) Schema(ctx context.Context, apiVersion, kind string) (*apiextensionsv1.JSONSchemaProps, error) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	gvk := gv.WithKind(kind)
+	plural, ok := r.resource(gvk)
+	if !ok {
+		return nil, fmt.Errorf("unsupported resource: %s", gvk)
+	}
+	if r.crds == nil || plural == "" {
+		return nil, nil
+	}
+
+	r.mu.Lock()
+	cached, ok := r.schemas[gvk]
+	r.mu.Unlock()
+	if ok && time.Since(cached.fetched) < schemaCacheTTL {
+		return cached.schema, nil
+	}
+
+	// no lock is held while fetching, concurrent lookups of a kind share a single fetch that
+	// outlives a caller giving up
+	fetch := r.fetches.DoChan(gvk.String(), func() (any, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), schemaFetchTimeout)
+		defer cancel()
+		props, err := r.fetchSchema(fetchCtx, gvk, plural)
+		if err != nil {
+			return nil, err
+		}
+		r.mu.Lock()
+		r.schemas[gvk] = cachedSchema{schema: props, fetched: time.Now()}
+		r.mu.Unlock()
+		return props, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to get CRD of %s: %w", gvk, ctx.Err())
+	case result := <-fetch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		props, _ := result.Val.(*apiextensionsv1.JSONSchemaProps)
+		return props, nil
+	}
+}
+
+// fetchSchema returns the schema of the version of the kind from its CRD.
+func (r *ResourceManager) fetchSchema(ctx context.Context, gvk schema.GroupVersionKind, plural string) (*apiextensionsv1.JSONSchemaProps, error) {
+	crd, err := r.crds.Get(ctx, plural+"."+gvk.Group, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CRD of %s: %w", gvk, err)
+	}
+	for _, version := range crd.Spec.Versions {
+		if version.Name == gvk.Version && version.Schema != nil {
+			return version.Schema.OpenAPIV3Schema, nil
+		}
+	}
+	return nil, nil
+}
+
+// FieldPath splits a simple jq path like .status.host, .spec.items[0].name or .metadata.labels["app"]
+// into its steps, indexes and iterations of arrays become an IndexStep. It returns false for any
+// other query, those can't be checked against a schema.
+func FieldPath(query string) ([]string, bool) {
+	query = strings.TrimSpace(query)
+	if !strings.HasPrefix(query, ".") || query == "." {
+		return nil, false
+	}
+	var steps []string
+	for i := 0; i < len(query); {
+		switch query[i] {
+		case '.':
+			i++
+			switch {
+			case i < len(query) && query[i] == '"':
+				name, n, ok := quotedPrefix(query[i:])
+				if !ok {
+					return nil, false
+				}
+				steps = append(steps, name)
+				i += n
+			case i < len(query) && query[i] == '[':
+				continue
+			default:
+				n := identifierLength(query[i:])
+				if n == 0 {
+					return nil, false
+				}
+				steps = append(steps, query[i:i+n])
+				i += n
+			}
+		case '[':
+			i++
+			switch {
+			case i < len(query) && query[i] == ']':
+				steps = append(steps, IndexStep)
+			case i < len(query) && query[i] == '"':
+				name, n, ok := quotedPrefix(query[i:])
+				if !ok {
+					return nil, false
+				}
+				steps = append(steps, name)
+				i += n
+			default:
+				n := strings.IndexFunc(query[i:], func(r rune) bool { return r < '0' || r > '9' })
+				if n <= 0 {
+					return nil, false
+				}
+				steps = append(steps, IndexStep)
+				i += n
+			}
+			if i >= len(query) || query[i] != ']' {
+				return nil, false
+			}
+			i++
+		default:
+			return nil, false
+		}
+		if i < len(query) && query[i] == '?' {
+			i++
+		}
+	}
+	return steps, true
+}
+
+func identifierLength(s string) int {
+	for i, r := range s {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9' {
+			continue
+		}
+		return i
+	}
+	return len(s)
+}
+
+func quotedPrefix(s string) (string, int, bool) {
+	quoted, err := strconv.QuotedPrefix(s)
+	if err != nil {
+		return "", 0, false
+	}
+	name, err := strconv.Unquote(quoted)
+	if err != nil {
+		return "", 0, false
+	}
+	return name, len(quoted), true
+}
+
+// CheckPath checks that the steps of a field path exist in a schema, an unknown field is reported
+// together with the closest field the schema defines. Parts of the schema that preserve unknown
+// fields or don't list their properties are not checked.
+func CheckPath(props *apiextensionsv1.JSONSchemaProps, steps []string) error {
+	current := props
+	for i, step := range steps {
+		if current == nil || current.XPreserveUnknownFields != nil && *current.XPreserveUnknownFields {
+			return nil
+		}
+		if step == IndexStep {
+			if current.Type != "" && current.Type != "array" {
+				return fmt.Errorf("field %s is of type %s, not an array", joinSteps(steps[:i]), current.Type)
+			}
+			if current.Items == nil {
+				return nil
+			}
+			current = current.Items.Schema
+			continue
+		}
+		if property, ok := current.Properties[step]; ok {
+			current = &property
+			continue
+		}
+		if additional := current.AdditionalProperties; additional != nil && (additional.Allows || additional.Schema != nil) {
+			current = additional.Schema
+			continue
+		}
+		if current.Type != "" && current.Type != "object" {
+			return fmt.Errorf("field %s is of type %s, not an object", joinSteps(steps[:i]), current.Type)
+		}
+		if len(current.Properties) == 0 {
+			return nil
+		}
+		names := make([]string, 0, len(current.Properties))
+		for name := range current.Properties {
+			names = append(names, name)
+		}
+		suggestion := append(append([]string{}, steps[:i]...), closest(step, names))
+		return fmt.Errorf("field %s does not exist, did you mean %s?", joinSteps(steps[:i+1]), joinSteps(suggestion))
+	}
+	return nil
+}
+
+func joinSteps(steps []string) string {
+	var b strings.Builder
+	for _, step := range steps {
+		if step == IndexStep {
+			b.WriteString(IndexStep)
+			continue
+		}
+		b.WriteString(".")
+		if identifierLength(step) == len(step) && step != "" {
+			b.WriteString(step)
+		} else {
+			b.WriteString(strconv.Quote(step))
+		}
+	}
+	if b.Len() == 0 {
+		return "."
+	}
+	return b.String()
+}
+
+// closest returns the name with the smallest edit distance to the given one, ties go to the
+// alphabetically first name.
+func closest(name string, names []string) string {
+	sort.Strings(names)
+	best, bestDistance := "", -1
+	for _, candidate := range names {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(candidate)); bestDistance < 0 || d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
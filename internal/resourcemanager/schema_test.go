@@ -0,0 +1,217 @@
+package resourcemanager
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+func TestFieldPath(t *testing.T) {
+	for _, tc := range []struct {
+		query         string
+		expectedSteps []string
+		expectSimple  bool
+	}{
+		{query: ".status.host", expectedSteps: []string{"status", "host"}, expectSimple: true},
+		{query: " .status.host ", expectedSteps: []string{"status", "host"}, expectSimple: true},
+		{query: ".status.nodes[0].id", expectedSteps: []string{"status", "nodes", IndexStep, "id"}, expectSimple: true},
+		{query: ".status.nodes[].id", expectedSteps: []string{"status", "nodes", IndexStep, "id"}, expectSimple: true},
+		{query: ".status.nodes.[0].id", expectedSteps: []string{"status", "nodes", IndexStep, "id"}, expectSimple: true},
+		{query: `.metadata.labels["app.kubernetes.io/name"]`, expectedSteps: []string{"metadata", "labels", "app.kubernetes.io/name"}, expectSimple: true},
+		{query: `.metadata.annotations."cnrm.cloud.google.com/project-id"`, expectedSteps: []string{"metadata", "annotations", "cnrm.cloud.google.com/project-id"}, expectSimple: true},
+		{query: ".status.readEndpoint?", expectedSteps: []string{"status", "readEndpoint"}, expectSimple: true},
+		{query: "."},
+		{query: ".status.port | tostring"},
+		{query: `.status.conditions[] | select(.type == "Ready")`},
+		{query: "..|.host?"},
+		{query: ".status.nodes[-1]"},
+		{query: ".status."},
+		{query: "status.host"},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			steps, simple := FieldPath(tc.query)
+			require.Equal(t, tc.expectSimple, simple)
+			require.Equal(t, tc.expectedSteps, steps)
+		})
+	}
+}
+
+func TestCheckPath(t *testing.T) {
+	props := redisInstanceSchema(t)
+	for _, tc := range []struct {
+		name      string
+		query     string
+		expectErr string
+	}{
+		{name: "existing field", query: ".status.host"},
+		{name: "array item field", query: ".status.nodes[0].zone"},
+		{name: "additional properties", query: `.spec.redisConfigs["maxmemory-policy"]`},
+		{name: "metadata is not described by CRDs", query: ".metadata.annotations.owner"},
+		{
+			name:      "typo",
+			query:     ".status.hostt",
+			expectErr: "field .status.hostt does not exist, did you mean .status.host?",
+		},
+		{
+			name:      "case mismatch",
+			query:     ".status.readendpointport",
+			expectErr: "field .status.readendpointport does not exist, did you mean .status.readEndpointPort?",
+		},
+		{
+			name:      "array item typo",
+			query:     ".status.nodes[].zones",
+			expectErr: "field .status.nodes[].zones does not exist, did you mean .status.nodes[].zone?",
+		},
+		{
+			name:      "field of a string",
+			query:     ".status.host.name",
+			expectErr: "field .status.host is of type string, not an object",
+		},
+		{
+			name:      "index of an object",
+			query:     ".status[0]",
+			expectErr: "field .status is of type object, not an array",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			steps, ok := FieldPath(tc.query)
+			require.True(t, ok)
+			err := CheckPath(props, steps)
+			if tc.expectErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.expectErr)
+		})
+	}
+}
+
+func TestRMSchema(t *testing.T) {
+	redisGVK := schema.GroupVersionKind{Group: "redis.cnrm.cloud.google.com", Version: "v1beta1", Kind: "RedisInstance"}
+	sqlGVK := schema.GroupVersionKind{Group: "sql.cnrm.cloud.google.com", Version: "v1beta1", Kind: "SQLInstance"}
+	rm, err := NewResourceManager(&testPreferredResources{gvks: []schema.GroupVersionKind{redisGVK, sqlGVK}}, DefaultGroups)
+	require.NoError(t, err)
+
+	props, err := rm.Schema(context.Background(), "redis.cnrm.cloud.google.com/v1beta1", "RedisInstance")
+	require.NoError(t, err)
+	require.Nil(t, props, "schemas are not fetched unless enabled")
+
+	crds := &testCRDs{crds: map[string]*apiextensionsv1.CustomResourceDefinition{
+		"redisinstances.redis.cnrm.cloud.google.com": redisInstanceCRD(t),
+	}}
+	rm.WithSchemas(crds)
+	for i := 0; i < 3; i++ {
+		props, err = rm.Schema(context.Background(), "redis.cnrm.cloud.google.com/v1beta1", "RedisInstance")
+		require.NoError(t, err)
+		require.Contains(t, props.Properties, "status")
+	}
+	require.Equal(t, 1, crds.gets["redisinstances.redis.cnrm.cloud.google.com"], "schemas are cached")
+
+	_, err = rm.Schema(context.Background(), "sql.cnrm.cloud.google.com/v1beta1", "SQLInstance")
+	require.ErrorContains(t, err, "failed to get CRD of sql.cnrm.cloud.google.com/v1beta1, Kind=SQLInstance")
+
+	_, err = rm.Schema(context.Background(), "sql.cnrm.cloud.google.com/v1beta1", "SQLUser")
+	require.EqualError(t, err, "unsupported resource: sql.cnrm.cloud.google.com/v1beta1, Kind=SQLUser")
+}
+
+func TestRMSchemaConcurrentFetches(t *testing.T) {
+	redisGVK := schema.GroupVersionKind{Group: "redis.cnrm.cloud.google.com", Version: "v1beta1", Kind: "RedisInstance"}
+	sqlGVK := schema.GroupVersionKind{Group: "sql.cnrm.cloud.google.com", Version: "v1beta1", Kind: "SQLInstance"}
+	rm, err := NewResourceManager(&testPreferredResources{gvks: []schema.GroupVersionKind{redisGVK, sqlGVK}}, DefaultGroups)
+	require.NoError(t, err)
+	crds := &slowCRDs{
+		crd:     redisInstanceCRD(t),
+		slow:    "sqlinstances.sql.cnrm.cloud.google.com",
+		release: make(chan struct{}),
+	}
+	rm.WithSchemas(crds)
+
+	// a slow fetch neither blocks lookups of other kinds nor callers that give up
+	slow := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := rm.Schema(context.Background(), "sql.cnrm.cloud.google.com/v1beta1", "SQLInstance")
+			slow <- err
+		}()
+	}
+	require.Eventually(t, func() bool { return crds.gets.Load() == 1 }, time.Second, time.Millisecond)
+
+	props, err := rm.Schema(context.Background(), "redis.cnrm.cloud.google.com/v1beta1", "RedisInstance")
+	require.NoError(t, err)
+	require.Contains(t, props.Properties, "status")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = rm.Schema(ctx, "sql.cnrm.cloud.google.com/v1beta1", "SQLInstance")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(crds.release)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, <-slow)
+	}
+	require.Equal(t, int32(2), crds.gets.Load(), "concurrent lookups of a kind share a fetch")
+}
+
+// slowCRDs serves the same CRD for every name, fetching the slow one blocks until released.
+type slowCRDs struct {
+	crd     *apiextensionsv1.CustomResourceDefinition
+	slow    string
+	release chan struct{}
+	gets    atomic.Int32
+}
+
+func (c *slowCRDs) Get(ctx context.Context, name string, _ metav1.GetOptions) (*apiextensionsv1.CustomResourceDefinition, error) {
+	c.gets.Add(1)
+	if name == c.slow {
+		select {
+		case <-c.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return c.crd, nil
+}
+
+type testCRDs struct {
+	crds map[string]*apiextensionsv1.CustomResourceDefinition
+	gets map[string]int
+}
+
+func (c *testCRDs) Get(_ context.Context, name string, _ metav1.GetOptions) (*apiextensionsv1.CustomResourceDefinition, error) {
+	if c.gets == nil {
+		c.gets = make(map[string]int)
+	}
+	c.gets[name]++
+	crd, ok := c.crds[name]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return crd, nil
+}
+
+func redisInstanceCRD(t *testing.T) *apiextensionsv1.CustomResourceDefinition {
+	data, err := os.ReadFile("../../hack/config-connector-crds/redisinstance.yaml")
+	require.NoError(t, err)
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	require.NoError(t, yaml.Unmarshal(data, crd))
+	return crd
+}
+
+func redisInstanceSchema(t *testing.T) *apiextensionsv1.JSONSchemaProps {
+	for _, version := range redisInstanceCRD(t).Spec.Versions {
+		if version.Name == "v1beta1" {
+			return version.Schema.OpenAPIV3Schema
+		}
+	}
+	t.Fatal("no v1beta1 schema")
+	return nil
+}
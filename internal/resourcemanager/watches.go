@@ -0,0 +1,28 @@
+package resourcemanager
+
+import (
+	"context"
+	"errors"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Watches starts and stops the watches on source kinds, it is implemented by the
+// ResourceFieldExport reconciler.
+type Watches interface {
+	StartWatch(ctx context.Context, gvk schema.GroupVersionKind) error
+	StopWatch(ctx context.Context, gvk schema.GroupVersionKind) error
+}
+
+// UpdateWatches starts watching the kinds that became supported and stops watching those that
+// no longer are.
+func UpdateWatches(ctx context.Context, watches Watches, added, removed []schema.GroupVersionKind) error {
+	var errs []error
+	for _, gvk := range added {
+		errs = append(errs, watches.StartWatch(ctx, gvk))
+	}
+	for _, gvk := range removed {
+		errs = append(errs, watches.StopWatch(ctx, gvk))
+	}
+	return errors.Join(errs...)
+}